@@ -0,0 +1,248 @@
+package dsn
+
+import "crypto/tls"
+
+// builtinDriver is a Driver described by its fields, used for the built-in drivers.
+type builtinDriver struct {
+	name        string
+	aliases     []string
+	defaultPort string
+	parameters  []ParameterSpec
+	// foldParameters makes the parameter names case-insensitive, as ADO.NET keywords.
+	foldParameters bool
+	validate       func(d *DSN) error
+	format         func(d *DSN) (string, error)
+	tlsConfig      func(d *DSN) (*tls.Config, error)
+}
+
+// Name returns the canonical scheme of the engine.
+func (b *builtinDriver) Name() string {
+	return b.name
+}
+
+// Aliases returns the other schemes designating the engine.
+func (b *builtinDriver) Aliases() []string {
+	return append([]string(nil), b.aliases...)
+}
+
+// DefaultPort returns the default port of the engine.
+func (b *builtinDriver) DefaultPort() string {
+	return b.defaultPort
+}
+
+// Parameters returns the parameters known by the engine.
+func (b *builtinDriver) Parameters() []ParameterSpec {
+	return append([]ParameterSpec(nil), b.parameters...)
+}
+
+// foldsParameters reports whether the parameter names are case-insensitive.
+func (b *builtinDriver) foldsParameters() bool {
+	return b.foldParameters
+}
+
+// Validate checks that the DSN follows the rules of the engine.
+func (b *builtinDriver) Validate(d *DSN) error {
+	if b.validate == nil {
+		return nil
+	}
+	return b.validate(d)
+}
+
+// TLSConfig returns the TLS configuration of the DSN, or nil if TLS is disabled.
+func (b *builtinDriver) TLSConfig(d *DSN) (*tls.Config, error) {
+	if b.tlsConfig == nil {
+		return genericTLSConfig(d)
+	}
+	return b.tlsConfig(d)
+}
+
+// Format returns the DSN in the native format of the engine.
+func (b *builtinDriver) Format(d *DSN) (string, error) {
+	if b.format == nil {
+		return d.Encode(), nil
+	}
+	return b.format(d)
+}
+
+// specs returns the specifications of parameters of the same type, without restricted values.
+func specs(typ ParameterType, names ...string) []ParameterSpec {
+	parameters := make([]ParameterSpec, 0, len(names))
+	for _, name := range names {
+		parameters = append(parameters, ParameterSpec{Name: name, Type: typ})
+	}
+	return parameters
+}
+
+// oneOf returns the specification of a parameter restricted to the allowed values.
+func oneOf(name string, allowed ...string) []ParameterSpec {
+	return []ParameterSpec{{Name: name, Allowed: allowed}}
+}
+
+// join concatenates lists of parameter specifications.
+func join(lists ...[]ParameterSpec) []ParameterSpec {
+	var parameters []ParameterSpec
+	for _, list := range lists {
+		parameters = append(parameters, list...)
+	}
+	return parameters
+}
+
+func init() {
+	Register(&builtinDriver{
+		name:        "postgres",
+		aliases:     []string{"postgresql", "pg"},
+		defaultPort: "5432",
+		parameters: join(
+			specs(ParameterString, "host", "hostaddr", "port", "dbname", "user", "password", "passfile",
+				"client_encoding", "options", "application_name", "fallback_application_name", "replication",
+				"requiressl", "sslcert", "sslkey", "sslpassword", "sslrootcert", "sslcrl", "sslcrldir",
+				"requirepeer", "krbsrvname", "gsslib", "gssdelegation", "service", "search_path"),
+			specs(ParameterInt, "connect_timeout", "keepalives", "keepalives_idle", "keepalives_interval",
+				"keepalives_count", "tcp_user_timeout", "pool_max_conns", "pool_min_conns",
+				"statement_cache_capacity"),
+			specs(ParameterStrictDuration, "pool_max_conn_lifetime", "pool_max_conn_idle_time",
+				"pool_health_check_period"),
+			oneOf("sslmode", postgresSSLModes...),
+			oneOf("channel_binding", "disable", "prefer", "require"),
+			oneOf("gssencmode", "disable", "prefer", "require"),
+			oneOf("sslcertmode", "disable", "allow", "require"),
+			oneOf("sslnegotiation", "postgres", "direct"),
+			oneOf("sslcompression", "0", "1"),
+			oneOf("sslsni", "0", "1"),
+			oneOf("ssl_min_protocol_version", "TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"),
+			oneOf("ssl_max_protocol_version", "TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"),
+			oneOf("target_session_attrs", "any", "read-write", "read-only", "primary", "standby",
+				"prefer-standby"),
+			oneOf("load_balance_hosts", "disable", "random"),
+			oneOf("default_query_exec_mode", "cache_statement", "cache_describe", "describe_exec", "exec",
+				"simple_protocol"),
+		),
+		format:    func(d *DSN) (string, error) { return d.GetPostgresURI(), nil },
+		tlsConfig: postgresTLSConfig,
+	})
+	Register(&builtinDriver{
+		name:        "mysql",
+		aliases:     []string{"mariadb", "mysql2"},
+		defaultPort: "3306",
+		parameters: join(
+			specs(ParameterString, "charset", "collation", "connectionAttributes", "loc", "serverPubKey",
+				"socket", "time_zone", "sql_mode", "autocommit", "transaction_isolation", "tx_isolation"),
+			specs(ParameterBool, "allowAllFiles", "allowCleartextPasswords", "allowFallbackToPlaintext",
+				"allowNativePasswords", "allowOldPasswords", "checkConnLiveness", "clientFoundRows",
+				"columnsWithAlias", "interpolateParams", "multiStatements", "parseTime", "rejectReadOnly"),
+			specs(ParameterInt, "maxAllowedPacket"),
+			specs(ParameterStrictDuration, "timeTruncate", "timeout", "readTimeout", "writeTimeout"),
+			oneOf("tls", mysqlTLSModes...),
+		),
+		format:    func(d *DSN) (string, error) { return d.GetMySQLDSN(), nil },
+		tlsConfig: mysqlTLSConfig,
+	})
+	Register(&builtinDriver{
+		name:           "sqlserver",
+		aliases:        []string{"mssql"},
+		defaultPort:    "1433",
+		foldParameters: true,
+		parameters: join(
+			specs(ParameterString, "database", "certificate", "hostNameInCertificate", "ServerSPN",
+				"Workstation ID", "app name", "failoverpartner", "protocol", "fedauth", "user id", "password"),
+			specs(ParameterBool, "TrustServerCertificate", "disableretry", "MultiSubnetFailover",
+				"columnencryption"),
+			specs(ParameterInt, "failoverport", "connection timeout", "dial timeout", "keepAlive",
+				"packet size", "log"),
+			oneOf("encrypt", "true", "false", "yes", "no", "disable", "strict", "mandatory", "optional"),
+			oneOf("tlsmin", "1.0", "1.1", "1.2", "1.3"),
+			oneOf("ApplicationIntent", "ReadOnly", "ReadWrite"),
+		),
+		format:    func(d *DSN) (string, error) { return d.GetADOConnectionString(), nil },
+		tlsConfig: sqlServerTLSConfig,
+	})
+	Register(&builtinDriver{
+		name:        "redis",
+		defaultPort: "6379",
+		parameters:  redisParameters,
+		tlsConfig:   redisTLSConfig,
+	})
+	Register(&builtinDriver{
+		name:        "rediss",
+		defaultPort: "6379",
+		parameters:  redisParameters,
+		tlsConfig:   redisTLSConfig,
+	})
+	Register(&builtinDriver{
+		name:           "mongodb",
+		defaultPort:    "27017",
+		foldParameters: true,
+		parameters: join(
+			specs(ParameterString, "appName", "authMechanismProperties", "authSource", "compressors",
+				"proxyHost", "proxyUsername", "proxyPassword", "readPreferenceTags", "replicaSet",
+				"srvServiceName", "tlsCAFile", "tlsCertificateKeyFile", "tlsCertificateKeyFilePassword", "w"),
+			specs(ParameterBool, "directConnection", "journal", "loadBalanced", "retryReads", "retryWrites",
+				"serverSelectionTryOnce", "ssl", "tls", "tlsAllowInvalidCertificates",
+				"tlsAllowInvalidHostnames", "tlsDisableCertificateRevocationCheck",
+				"tlsDisableOCSPEndpointCheck", "tlsInsecure"),
+			specs(ParameterInt, "connectTimeoutMS", "heartbeatFrequencyMS", "localThresholdMS",
+				"maxConnecting", "maxIdleTimeMS", "maxPoolSize", "maxStalenessSeconds", "minPoolSize",
+				"proxyPort", "serverSelectionTimeoutMS", "socketTimeoutMS", "srvMaxHosts", "timeoutMS",
+				"waitQueueTimeoutMS", "wTimeoutMS", "zlibCompressionLevel"),
+			oneOf("authMechanism", "SCRAM-SHA-1", "SCRAM-SHA-256", "MONGODB-X509", "MONGODB-AWS", "GSSAPI",
+				"PLAIN", "MONGODB-OIDC"),
+			oneOf("readConcernLevel", "local", "majority", "linearizable", "available", "snapshot"),
+			oneOf("readPreference", "primary", "primaryPreferred", "secondary", "secondaryPreferred",
+				"nearest"),
+			oneOf("serverMonitoringMode", "auto", "poll", "stream"),
+		),
+		validate: func(d *DSN) error {
+			if err := d.srvFieldError(); err != nil {
+				return err
+			}
+			return nil
+		},
+		tlsConfig: mongoTLSConfig,
+	})
+	Register(&builtinDriver{
+		name:    "sqlite",
+		aliases: []string{"sqlite3", "file"},
+		parameters: join(
+			specs(ParameterString, "vfs", "modeof", "_auth_user", "_auth_pass", "_auth_crypt", "_auth_salt",
+				"_auto_vacuum", "_journal_mode", "_locking_mode", "_loc", "_synchronous", "_pragma",
+				"_time_format", "_secure_delete"),
+			specs(ParameterBool, "immutable", "nolock", "psow", "_auth", "_case_sensitive_like",
+				"_defer_foreign_keys", "_foreign_keys", "_ignore_check_constraints", "_query_only",
+				"_recursive_triggers"),
+			specs(ParameterInt, "_busy_timeout", "_cache_size"),
+			oneOf("cache", "shared", "private"),
+			oneOf("mode", "ro", "rw", "rwc", "memory"),
+			oneOf("_mutex", "no", "full"),
+			oneOf("_txlock", "immediate", "deferred", "exclusive"),
+		),
+	})
+	Register(&builtinDriver{
+		name:        "oracle",
+		defaultPort: "1521",
+		parameters: join(
+			specs(ParameterString, "ssl_server_cert_dn", "wallet_location", "trace file", "auth type",
+				"unix socket", "lob fetch"),
+			specs(ParameterBool, "ssl", "ssl verify", "ssl_server_dn_match", "os auth"),
+			specs(ParameterInt, "connect_timeout", "transport_connect_timeout", "retry_count",
+				"retry_delay", "expire_time", "sdu", "timeout", "prefetch_rows"),
+		),
+	})
+}
+
+// postgresSSLModes are the values of the sslmode parameter of PostgreSQL.
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// mysqlTLSModes are the values of the tls parameter of go-sql-driver/mysql.
+var mysqlTLSModes = []string{"true", "false", "skip-verify", "preferred"}
+
+// redisParameters are the parameters known by the Redis drivers.
+var redisParameters = join(
+	specs(ParameterString, "client_name", "master_name", "masterName", "sentinelMasterId", "addr",
+		"sentinel_username", "sentinel_password"),
+	specs(ParameterBool, "pool_fifo", "skip_verify", "tls", "ssl"),
+	specs(ParameterInt, "db", "max_retries", "pool_size", "min_idle_conns", "max_idle_conns",
+		"max_active_conns"),
+	specs(ParameterDuration, "min_retry_backoff", "max_retry_backoff", "dial_timeout", "read_timeout",
+		"write_timeout", "pool_timeout", "conn_max_idle_time", "conn_max_lifetime"),
+	oneOf("protocol", "2", "3"),
+)
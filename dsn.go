@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Error messages.
@@ -19,87 +20,116 @@ var (
 type DSN struct {
 	dsn        string
 	scheme     string
+	suffix     string
+	fileURI    bool
 	user       string
 	password   string
-	host       string
-	port       string
+	hosts      []HostPort
 	dbname     string
-	parameters map[string]string
+	instance   string
+	parameters parameters
+	modified   bool
 }
 
 // New creates a new DSN from a string.
 // Format: <scheme>://<user>:<password>@<host>:<port>/<dbname>?<parameters>.
-//
-//nolint:cyclop
-func New(dsn string) (*DSN, error) {
-	// Reject paths that start with / and don't have a scheme or host part
-	if strings.HasPrefix(dsn, "/") && !strings.Contains(dsn, "://") {
-		return nil, fmt.Errorf("%w", ErrInvalidDSNFormat)
+// Several hosts can be given, separated by commas: <host1>:<port1>,<host2>:<port2>.
+// The go-sql-driver/mysql format <user>:<password>@tcp(<host>:<port>)/<dbname>?<parameters>
+// the libpq format host=<host> port=<port> user=<user> dbname=<dbname> <parameter>=<value>
+// the ADO.NET format Server=<host>,<port>;Database=<dbname>;User Id=<user>;Password=<password>
+// JDBC URLs jdbc:<scheme>://<host>:<port>/<dbname>?user=<user>&password=<password>
+// the Oracle formats <user>/<password>@//<host>:<port>/<service_name> and (DESCRIPTION=...)
+// and the SQLite formats sqlite://<path>, file:<path> and :memory: are also supported.
+// Options add defaults and checks, see ParseOption.
+func New(dsn string, opts ...ParseOption) (*DSN, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
-
-	// Support for simple hostname case
-	if !strings.Contains(dsn, "://") && !strings.Contains(dsn, "@") && !strings.Contains(dsn, "/") {
-		d := DSN{
-			dsn:        dsn,
-			host:       dsn,
-			parameters: make(map[string]string),
+	if o.expand {
+		expanded, err := Expand(dsn, o.lookup)
+		if err != nil {
+			return nil, err
 		}
-		return &d, nil
+		dsn = expanded
+	}
+	d, err := parse(dsn)
+	if err != nil {
+		return nil, err
 	}
+	if err := o.apply(d, dsn); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
 
-	// Ensure scheme for url.Parse to work correctly
-	parsableDSN := dsn
-	if !strings.Contains(parsableDSN, "://") {
-		parsableDSN = "placeholder://" + parsableDSN
+// parse creates a new DSN from a string in one of the formats supported by New.
+func parse(dsn string) (*DSN, error) {
+	if !utf8.ValidString(dsn) {
+		return nil, fmt.Errorf("%w: invalid UTF-8", ErrInvalidDSNFormat)
 	}
 
-	u, err := url.Parse(parsableDSN)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrInvalidDSNFormat, err.Error())
+	// Support for JDBC URLs
+	if isJDBCDSN(dsn) {
+		return parseJDBC(dsn)
 	}
 
-	// Extract scheme, accounting for the placeholder
-	scheme := u.Scheme
-	if scheme == "placeholder" {
-		scheme = ""
+	// Support for SQLite file paths
+	if isSQLiteDSN(dsn) {
+		return parseSQLite(dsn)
 	}
 
-	// Extract user and password
-	user := ""
-	password := ""
-	if u.User != nil {
-		user = u.User.Username()
-		if pass, ok := u.User.Password(); ok {
-			password = pass
-		}
+	// Support for ADO.NET format
+	if isADODSN(dsn) {
+		return parseADO(dsn)
+	}
+
+	// Support for libpq keyword/value format
+	if isLibpqDSN(dsn) {
+		return parseLibpq(dsn)
+	}
+
+	// Support for go-sql-driver/mysql format
+	if isMySQLDSN(dsn) {
+		return parseMySQL(dsn)
 	}
 
-	// Extract host and port
-	host := u.Hostname()
-	port := u.Port()
+	// Support for Oracle formats
+	if isOracleDSN(dsn) {
+		return parseOracle(dsn)
+	}
 
-	// Extract path (dbname)
-	dbname := strings.TrimPrefix(u.Path, "/")
+	// Reject paths that start with / and don't have a scheme or host part
+	if strings.HasPrefix(dsn, "/") && !strings.Contains(dsn, "://") {
+		return nil, fmt.Errorf("%w", ErrInvalidDSNFormat)
+	}
 
-	// Extract parameters
-	parameters := make(map[string]string)
-	for k, values := range u.Query() {
-		if len(values) > 0 {
-			parameters[k] = values[len(values)-1] // Use the last value if multiple exist
+	// Support for simple hostname case
+	if isBareHost(dsn) {
+		// host, host:port, or an IPv6 address, bracketed with an optional port
+		h, err := parseHostPort(dsn)
+		if err != nil {
+			return nil, err
 		}
+		return &DSN{dsn: dsn, hosts: []HostPort{h}, parameters: parameters{}}, nil
 	}
 
-	d := DSN{
-		dsn:        dsn,
-		scheme:     scheme,
-		user:       user,
-		password:   password,
-		host:       host,
-		port:       port,
-		dbname:     dbname,
-		parameters: parameters,
+	d, err := parseURL(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if isSQLServerScheme(d.CanonicalScheme()) {
+		d.fromSQLServerURL()
 	}
-	return &d, nil
+	if err := d.validateSRV(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// isBareHost reports whether dsn is a bare hostname, without scheme, user or path.
+func isBareHost(dsn string) bool {
+	return !strings.Contains(dsn, "://") && !strings.Contains(dsn, "@") && !strings.Contains(dsn, "/")
 }
 
 // GetUser returns the username component of the DSN.
@@ -113,21 +143,42 @@ func (d *DSN) GetPassword() string {
 }
 
 // GetHost returns the hostname component of the DSN.
+// For a multi-host DSN, the first host is returned; use Hosts to get all of them.
 func (d *DSN) GetHost() string {
-	return d.host
+	return d.firstHost().Host
 }
 
 // GetPort returns the port component of the DSN or the default port if not specified.
+// For a multi-host DSN, the port of the first host is returned.
 func (d *DSN) GetPort(defaultPort string) string {
-	if d.port == "" {
-		return defaultPort
+	if port := d.firstHost().Port; port != "" {
+		return port
 	}
-	return d.port
+	return defaultPort
+}
+
+// EffectivePort returns the port component of the DSN or, if not specified, the default port of the
+// driver registered for its scheme (5432 for postgres, 3306 for mysql...).
+// Redis Sentinel schemes default to the Sentinel port 26379.
+// It returns an empty string if the scheme has no registered driver, if the driver has no default port
+// or for a mongodb+srv DSN whose ports are resolved through DNS.
+func (d *DSN) EffectivePort() string {
+	if port := d.firstHost().Port; port != "" {
+		return port
+	}
+	if d.IsSRV() {
+		return ""
+	}
+	if isOneOf(d.fullScheme(), redisSentinelSchemes) {
+		return defaultSentinelPort
+	}
+	return defaultPortOf(d.scheme)
 }
 
 // GetPortInt returns the port component of the DSN as an integer or the default port if not specified or invalid.
+// For a multi-host DSN, the port of the first host is used.
 func (d *DSN) GetPortInt(defaultPort int) int {
-	portInt, err := strconv.Atoi(d.port)
+	portInt, err := strconv.Atoi(d.firstHost().Port)
 	if err != nil {
 		return defaultPort
 	}
@@ -135,20 +186,21 @@ func (d *DSN) GetPortInt(defaultPort int) int {
 }
 
 // GetParameter returns the value of a specific parameter or empty string if not found.
+// If the parameter is repeated, the last value is returned.
 func (d *DSN) GetParameter(parameter string) string {
-	if val, exists := d.parameters[parameter]; exists {
-		return val
-	}
-	return ""
+	val, _ := d.parameters.get(parameter)
+	return val
+}
+
+// GetParameterValues returns all the values of a specific parameter, in order.
+func (d *DSN) GetParameterValues(parameter string) []string {
+	return d.parameters.values(parameter)
 }
 
 // GetParameters returns all parameters as a map.
+// If a parameter is repeated, the last value is kept.
 func (d *DSN) GetParameters() map[string]string {
-	paramsCopy := make(map[string]string, len(d.parameters))
-	for k, v := range d.parameters {
-		paramsCopy[k] = v
-	}
-	return paramsCopy
+	return d.parameters.toMap()
 }
 
 // GetDBName returns the database name component of the DSN.
@@ -156,22 +208,50 @@ func (d *DSN) GetDBName() string {
 	return d.dbname
 }
 
-// GetPostgresURI returns a PostgreSQL connection string format.
+// GetPostgresURI returns a PostgreSQL connection string format:
+// host=<host> port=<port> user=<user> password=<password> dbname=<dbname> <parameter>=<value>.
+// Values are quoted and escaped as required by libpq, empty components are omitted,
+// and all the parameters are passed through in their original order.
+// For a multi-host DSN, hosts and ports are emitted as comma-separated lists.
 func (d *DSN) GetPostgresURI() string {
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		d.GetHost(),
-		d.GetPort("5432"),
-		d.GetUser(),
-		d.GetPassword(),
-		d.GetDBName(),
-		d.GetParameter("sslmode"))
+	hostList := d.hosts
+	socket := d.SocketPath()
+	if socket != "" {
+		hostList = []HostPort{{Host: socket, Port: d.firstHost().Port}}
+	}
 
-	// Add other parameters that need special handling
-	if d.GetParameter("search_path") != "" {
-		psqlInfo += " search_path=" + d.GetParameter("search_path")
+	hosts := make([]string, 0, len(hostList))
+	ports := make([]string, 0, len(hostList))
+	for _, h := range hostList {
+		hosts = append(hosts, h.Host)
+		ports = append(ports, h.portOrDefault(defaultPortOf("postgres")))
+	}
+	if len(hostList) == 0 {
+		ports = append(ports, defaultPortOf("postgres"))
 	}
 
-	return psqlInfo
+	keywords := []parameter{
+		{key: "host", value: strings.Join(hosts, ",")},
+		{key: "port", value: strings.Join(ports, ",")},
+		{key: "user", value: d.user},
+		{key: "password", value: d.password},
+		{key: "dbname", value: d.dbname},
+	}
+
+	pairs := make([]string, 0, len(keywords)+len(d.parameters))
+	for _, kw := range keywords {
+		if kw.value != "" {
+			pairs = append(pairs, kw.key+"="+quoteLibpqValue(kw.value))
+		}
+	}
+	for _, param := range d.parameters {
+		if param.key == "host" && param.value == socket {
+			continue
+		}
+		pairs = append(pairs, param.key+"="+quoteLibpqValue(param.value))
+	}
+
+	return strings.Join(pairs, " ")
 }
 
 // GetScheme returns the scheme component of the DSN.
@@ -179,60 +259,228 @@ func (d *DSN) GetScheme() string {
 	return d.scheme
 }
 
-// String returns the original DSN string.
+// String returns the DSN as a string.
+// As long as the DSN has not been modified, the original string given to New is
+// returned unchanged. Once a setter has been called, String returns Encode.
 func (d *DSN) String() string {
+	if d.modified {
+		return d.Encode()
+	}
 	return d.dsn
 }
 
+// Encode rebuilds the DSN string from its components:
+// <scheme>://<user>:<password>@<host>:<port>/<dbname>?<parameters>.
+// User, password, dbname and parameters are percent-encoded,
+// and parameters are written in their original order.
+func (d *DSN) Encode() string {
+	return d.encode(false)
+}
+
 // StringRedacted returns the DSN with the password redacted.
 func (d *DSN) StringRedacted() string {
-	// If there's no password, just return the original
+	// If there's no password, just return the DSN as is
 	if d.password == "" {
-		return d.dsn
+		return d.String()
+	}
+	return d.encode(true)
+}
+
+// encode builds the string representation of the DSN from its components.
+// If redact is true, the password is replaced by ****.
+func (d *DSN) encode(redact bool) string {
+	if isSQLiteScheme(d.CanonicalScheme()) {
+		return d.encodeSQLite()
 	}
 
-	// Otherwise, construct a redacted version
 	var result strings.Builder
 
 	if d.scheme != "" {
-		result.WriteString(d.scheme)
+		result.WriteString(d.fullScheme())
 		result.WriteString("://")
 	}
 
-	if d.user != "" {
-		result.WriteString(d.user)
-		result.WriteString(":****@")
+	if d.user != "" || d.password != "" {
+		result.WriteString(escapeUserinfo(d.user))
+		if d.password != "" {
+			result.WriteString(":")
+			if redact {
+				result.WriteString("****")
+			} else {
+				result.WriteString(escapeUserinfo(d.password))
+			}
+		}
+		result.WriteString("@")
 	}
 
-	result.WriteString(d.host)
+	result.WriteString(encodeHosts(d.hosts))
 
-	if d.port != "" {
-		result.WriteString(":")
-		result.WriteString(d.port)
+	// SQL Server URLs hold the instance in the path and the database in a parameter
+	path, params := d.dbname, d.parameters
+	if isSQLServerScheme(d.CanonicalScheme()) {
+		path, params = d.instance, d.parameters
+		if d.dbname != "" {
+			params = append(parameters{{key: "database", value: d.dbname}}, params...)
+		}
 	}
 
-	if d.dbname != "" {
-		result.WriteString("/")
-		result.WriteString(d.dbname)
+	// MongoDB requires the hosts to be terminated by a slash when there are parameters
+	if path != "" || (len(params) > 0 && d.CanonicalScheme() == "mongodb") {
+		result.WriteString(escapePath("/" + path))
 	}
 
-	if len(d.parameters) > 0 {
+	if len(params) > 0 {
 		result.WriteString("?")
+		result.WriteString(params.encode())
+	}
 
-		paramStrs := make([]string, 0, len(d.parameters))
-		for k, v := range d.parameters {
-			paramStrs = append(paramStrs, k+"="+v)
-		}
+	return result.String()
+}
+
+// escapePath percent-encodes a path, keeping its slashes.
+func escapePath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
+
+// escapeUserinfo percent-encodes a user or password so that it can be placed
+// in the userinfo part of a URL.
+func escapeUserinfo(s string) string {
+	return url.User(s).String()
+}
+
+// Clone returns a deep copy of the DSN.
+func (d *DSN) Clone() *DSN {
+	c := *d
+	c.hosts = d.Hosts()
+	c.parameters = d.parameters.clone()
+	return &c
+}
+
+// SetScheme sets the scheme of the DSN.
+func (d *DSN) SetScheme(scheme string) *DSN {
+	d.scheme, d.suffix, d.fileURI = scheme, "", false
+	d.modified = true
+	return d
+}
+
+// SetUser sets the username of the DSN.
+func (d *DSN) SetUser(user string) *DSN {
+	d.user = user
+	d.modified = true
+	return d
+}
 
-		result.WriteString(strings.Join(paramStrs, "&"))
+// SetPassword sets the password of the DSN.
+func (d *DSN) SetPassword(password string) *DSN {
+	d.password = password
+	d.modified = true
+	return d
+}
+
+// SetHost sets the hostname of the DSN.
+// For a multi-host DSN, the first host is updated.
+func (d *DSN) SetHost(host string) *DSN {
+	if len(d.hosts) == 0 {
+		d.hosts = []HostPort{{}}
 	}
+	d.hosts[0].Host = host
+	d.modified = true
+	return d
+}
 
-	return result.String()
+// SetPort sets the port of the DSN. An empty port removes it.
+// For a multi-host DSN, the port of the first host is updated.
+func (d *DSN) SetPort(port string) *DSN {
+	if len(d.hosts) == 0 {
+		d.hosts = []HostPort{{}}
+	}
+	d.hosts[0].Port = port
+	d.modified = true
+	return d
+}
+
+// SetHosts replaces all the hosts of the DSN.
+func (d *DSN) SetHosts(hosts []HostPort) *DSN {
+	d.hosts = append([]HostPort(nil), hosts...)
+	d.modified = true
+	return d
+}
+
+// SetDBName sets the database name of the DSN.
+func (d *DSN) SetDBName(dbname string) *DSN {
+	d.dbname = dbname
+	d.modified = true
+	return d
 }
 
 // SetParameter sets or updates a parameter in the DSN.
-//
+// All the existing values of the parameter are replaced.
 func (d *DSN) SetParameter(key, value string) *DSN {
-	d.parameters[key] = value
+	d.parameters = d.parameters.set(key, value)
+	d.modified = true
+	return d
+}
+
+// AddParameter adds a value to a parameter, keeping the existing values.
+func (d *DSN) AddParameter(key, value string) *DSN {
+	d.parameters = d.parameters.add(key, value)
+	d.modified = true
+	return d
+}
+
+// DeleteParameter removes all the values of a parameter from the DSN.
+func (d *DSN) DeleteParameter(key string) *DSN {
+	d.parameters = d.parameters.del(key)
+	d.modified = true
 	return d
 }
+
+// WithScheme returns a copy of the DSN with the scheme set, leaving d unchanged.
+func (d *DSN) WithScheme(scheme string) *DSN {
+	return d.Clone().SetScheme(scheme)
+}
+
+// WithUser returns a copy of the DSN with the username set, leaving d unchanged.
+func (d *DSN) WithUser(user string) *DSN {
+	return d.Clone().SetUser(user)
+}
+
+// WithPassword returns a copy of the DSN with the password set, leaving d unchanged.
+func (d *DSN) WithPassword(password string) *DSN {
+	return d.Clone().SetPassword(password)
+}
+
+// WithHost returns a copy of the DSN with the hostname set, leaving d unchanged.
+func (d *DSN) WithHost(host string) *DSN {
+	return d.Clone().SetHost(host)
+}
+
+// WithPort returns a copy of the DSN with the port set, leaving d unchanged.
+func (d *DSN) WithPort(port string) *DSN {
+	return d.Clone().SetPort(port)
+}
+
+// WithHosts returns a copy of the DSN with the hosts replaced, leaving d unchanged.
+func (d *DSN) WithHosts(hosts []HostPort) *DSN {
+	return d.Clone().SetHosts(hosts)
+}
+
+// WithDBName returns a copy of the DSN with the database name set, leaving d unchanged.
+func (d *DSN) WithDBName(dbname string) *DSN {
+	return d.Clone().SetDBName(dbname)
+}
+
+// WithParameter returns a copy of the DSN with the parameter set, leaving d unchanged.
+func (d *DSN) WithParameter(key, value string) *DSN {
+	return d.Clone().SetParameter(key, value)
+}
+
+// WithAddedParameter returns a copy of the DSN with a value added to the parameter, leaving d unchanged.
+func (d *DSN) WithAddedParameter(key, value string) *DSN {
+	return d.Clone().AddParameter(key, value)
+}
+
+// WithoutParameter returns a copy of the DSN without the parameter, leaving d unchanged.
+func (d *DSN) WithoutParameter(key string) *DSN {
+	return d.Clone().DeleteParameter(key)
+}
@@ -0,0 +1,155 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ErrUnsetVariable is returned by Expand for a ${VAR:?message} whose variable is not set.
+var ErrUnsetVariable = errors.New("unset variable")
+
+// LookupFunc returns the value of a variable and whether it is set, as os.LookupEnv.
+type LookupFunc func(name string) (string, bool)
+
+// expansion is a ${...} reference found in a string given to Expand.
+type expansion struct {
+	start, end int // position of ${...} in the string
+	name       string
+	operator   string // "", "-", ":-", "?" or ":?"
+	word       string // default value or error message
+}
+
+// Expand substitutes the variables of s with the values returned by lookup (os.LookupEnv if nil):
+// ${VAR} is replaced by the value of VAR, empty if not set,
+// ${VAR:-default} by default if VAR is not set or empty (${VAR-default}: if not set),
+// and ${VAR:?message} returns an error wrapping ErrUnsetVariable if VAR is not set or empty
+// (${VAR?message}: if not set). A $ not followed by { is kept as is.
+// In a URL, the values substituted in the user and password are percent-encoded,
+// so that a password holding @ or : does not change how the DSN is parsed.
+func Expand(s string, lookup LookupFunc) (string, error) {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	expansions, err := findExpansions(s)
+	if err != nil {
+		return "", err
+	}
+	userinfoStart, userinfoEnd := userinfoBounds(s, expansions)
+
+	var result strings.Builder
+	last := 0
+	for _, e := range expansions {
+		result.WriteString(s[last:e.start])
+		last = e.end
+
+		value, err := e.expand(lookup)
+		if err != nil {
+			return "", err
+		}
+		if e.start >= userinfoStart && e.end <= userinfoEnd {
+			value = strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+		}
+		result.WriteString(value)
+	}
+	result.WriteString(s[last:])
+	return result.String(), nil
+}
+
+// expand returns the value of the expansion.
+func (e expansion) expand(lookup LookupFunc) (string, error) {
+	value, set := lookup(e.name)
+	switch e.operator {
+	case "-":
+		if !set {
+			return e.word, nil
+		}
+	case ":-":
+		if value == "" {
+			return e.word, nil
+		}
+	case "?", ":?":
+		if !set || (e.operator == ":?" && value == "") {
+			message := e.word
+			if message == "" {
+				message = "parameter null or not set"
+			}
+			return "", fmt.Errorf("%w: %s: %s", ErrUnsetVariable, e.name, message)
+		}
+	}
+	return value, nil
+}
+
+// findExpansions returns the ${...} references of s, in order.
+func findExpansions(s string) ([]expansion, error) {
+	var expansions []expansion
+	for i := 0; i < len(s); i++ {
+		if !strings.HasPrefix(s[i:], "${") {
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated variable at %d", ErrInvalidDSNFormat, i)
+		}
+		e := expansion{start: i, end: i + end + 1}
+		body := s[i+len("${") : i+end]
+
+		nameEnd := strings.IndexFunc(body, func(r rune) bool { return !isVariableChar(r) })
+		if nameEnd < 0 {
+			nameEnd = len(body)
+		}
+		e.name = body[:nameEnd]
+		if e.name == "" {
+			return nil, fmt.Errorf("%w: invalid variable %q", ErrInvalidDSNFormat, s[e.start:e.end])
+		}
+		rest := body[nameEnd:]
+		for _, op := range []string{":-", ":?", "-", "?"} {
+			if strings.HasPrefix(rest, op) {
+				e.operator, e.word = op, rest[len(op):]
+				break
+			}
+		}
+		if e.operator == "" && rest != "" {
+			return nil, fmt.Errorf("%w: invalid variable %q", ErrInvalidDSNFormat, s[e.start:e.end])
+		}
+
+		expansions = append(expansions, e)
+		i = e.end - 1
+	}
+	return expansions, nil
+}
+
+// isVariableChar reports whether r may appear in the name of a variable.
+func isVariableChar(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// userinfoBounds returns the bounds of the user and password of the URL s, ignoring the text
+// of its expansions, or an empty range if s is not a URL with userinfo.
+func userinfoBounds(s string, expansions []expansion) (int, int) {
+	// Blank the expansions so that the characters of their defaults are not taken as delimiters
+	masked := []byte(s)
+	for _, e := range expansions {
+		for i := e.start; i < e.end; i++ {
+			masked[i] = 'x'
+		}
+	}
+	literal := string(masked)
+
+	i := strings.Index(literal, "://")
+	if i < 0 {
+		return 0, 0
+	}
+	start := i + len("://")
+	authority := literal[start:]
+	if end := strings.IndexAny(authority, "/?#"); end >= 0 {
+		authority = authority[:end]
+	}
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return 0, 0
+	}
+	return start, start + at
+}
@@ -0,0 +1,180 @@
+package dsn
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// HostPort is a host of a DSN with its optional port.
+type HostPort struct {
+	Host string
+	Port string
+}
+
+// String returns the host and port joined as host:port, or the host alone if there is no port.
+// IPv6 addresses are enclosed in square brackets: [::1]:5432.
+func (h HostPort) String() string {
+	host := h.Host
+	if isIPv6(host) {
+		host = "[" + host + "]"
+	}
+	if h.Port == "" {
+		return host
+	}
+	return host + ":" + h.Port
+}
+
+// portOrDefault returns the port or defaultPort if the port is not specified.
+func (h HostPort) portOrDefault(defaultPort string) string {
+	if h.Port == "" {
+		return defaultPort
+	}
+	return h.Port
+}
+
+// Hosts returns all the hosts of the DSN, in order.
+func (d *DSN) Hosts() []HostPort {
+	return append([]HostPort(nil), d.hosts...)
+}
+
+// firstHost returns the first host of the DSN or an empty HostPort.
+func (d *DSN) firstHost() HostPort {
+	if len(d.hosts) == 0 {
+		return HostPort{}
+	}
+	return d.hosts[0]
+}
+
+// parseHosts parses a comma-separated list of host:port.
+func parseHosts(hostList string) ([]HostPort, error) {
+	if hostList == "" {
+		return nil, nil
+	}
+	var hosts []HostPort
+	for _, hostport := range strings.Split(hostList, ",") {
+		h, err := parseHostPort(hostport)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// parseHostPort parses a single host:port.
+// IPv6 addresses must be enclosed in square brackets, with an optional zone
+// identifier percent-encoded as %25: [fe80::1%25eth0]:5432.
+// An IPv6 address without brackets is accepted when there is no port.
+func parseHostPort(hostport string) (HostPort, error) {
+	if path, ok := cutParens(hostport, "unix"); ok {
+		return HostPort{Host: path}, nil
+	}
+	if addr, ok := cutParens(hostport, "tcp"); ok {
+		return parseHostPort(addr)
+	}
+	if strings.HasPrefix(hostport, "[") {
+		return parseIPv6HostPort(hostport)
+	}
+	if isIPv6(hostport) {
+		return HostPort{Host: hostport}, nil
+	}
+
+	host, port := hostport, ""
+	if i := strings.LastIndex(hostport, ":"); i >= 0 {
+		host, port = hostport[:i], hostport[i+1:]
+	}
+	if !isValidPort(port) {
+		return HostPort{}, fmt.Errorf("%w: invalid port %q", ErrInvalidDSNFormat, port)
+	}
+	host, err := unescape(host)
+	if err != nil {
+		return HostPort{}, err
+	}
+	return HostPort{Host: host, Port: port}, nil
+}
+
+// parseIPv6HostPort parses [address%25zone]:port.
+func parseIPv6HostPort(hostport string) (HostPort, error) {
+	end := strings.Index(hostport, "]")
+	if end < 0 {
+		return HostPort{}, fmt.Errorf("%w: missing ']' in host %q", ErrInvalidDSNFormat, hostport)
+	}
+	host := hostport[1:end]
+	port := ""
+	if rest := hostport[end+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ":") {
+			return HostPort{}, fmt.Errorf("%w: invalid host %q", ErrInvalidDSNFormat, hostport)
+		}
+		port = rest[1:]
+	}
+	if !isValidPort(port) {
+		return HostPort{}, fmt.Errorf("%w: invalid port %q", ErrInvalidDSNFormat, port)
+	}
+
+	addr, zone, hasZone := strings.Cut(host, "%25")
+	if !isIPv6(addr) {
+		return HostPort{}, fmt.Errorf("%w: invalid IPv6 address %q", ErrInvalidDSNFormat, addr)
+	}
+	if hasZone {
+		zone, err := unescape(zone)
+		if err != nil {
+			return HostPort{}, err
+		}
+		addr += "%" + zone
+	}
+	return HostPort{Host: addr, Port: port}, nil
+}
+
+// cutParens returns the content of prefix(...) if s has this form.
+func cutParens(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix+"(") || !strings.HasSuffix(s, ")") {
+		return "", false
+	}
+	return s[len(prefix)+1 : len(s)-1], true
+}
+
+// isIPv6 reports whether host is an IPv6 address, with an optional zone identifier.
+func isIPv6(host string) bool {
+	addr, _, _ := strings.Cut(host, "%")
+	ip := net.ParseIP(addr)
+	return ip != nil && strings.Contains(addr, ":")
+}
+
+// isValidPort reports whether port is empty or only made of digits.
+func isValidPort(port string) bool {
+	for _, c := range port {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeHosts returns the hosts as a comma-separated list of host:port,
+// escaped to be placed in a URL.
+func encodeHosts(hosts []HostPort) string {
+	hostStrs := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		hostStr := encodeHost(h.Host)
+		if h.Port != "" {
+			hostStr += ":" + h.Port
+		}
+		hostStrs = append(hostStrs, hostStr)
+	}
+	return strings.Join(hostStrs, ",")
+}
+
+// encodeHost escapes a host to be placed in a URL.
+// IPv6 addresses are enclosed in square brackets and their zone identifier is encoded as %25.
+func encodeHost(host string) string {
+	if isIPv6(host) {
+		addr, zone, hasZone := strings.Cut(host, "%")
+		if hasZone {
+			addr += "%25" + url.PathEscape(zone)
+		}
+		return "[" + addr + "]"
+	}
+	return url.PathEscape(host)
+}
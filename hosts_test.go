@@ -0,0 +1,261 @@
+package dsn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+func TestDSN_Hosts(t *testing.T) {
+	tests := []struct {
+		name      string
+		dsnToTest string
+		want      []dsn.HostPort
+		wantHost  string
+		wantPort  string
+		wantErr   bool
+	}{
+		{
+			name:      "single host",
+			dsnToTest: "postgres://u:p@host:5432/db",
+			want:      []dsn.HostPort{{Host: "host", Port: "5432"}},
+			wantHost:  "host",
+			wantPort:  "5432",
+			wantErr:   false,
+		},
+		{
+			name:      "no host",
+			dsnToTest: "postgres:///db",
+			want:      nil,
+			wantHost:  "",
+			wantPort:  "",
+			wantErr:   false,
+		},
+		{
+			name:      "postgres cluster",
+			dsnToTest: "postgres://u:p@h1:5432,h2:5433/db?target_session_attrs=read-write",
+			want:      []dsn.HostPort{{Host: "h1", Port: "5432"}, {Host: "h2", Port: "5433"}},
+			wantHost:  "h1",
+			wantPort:  "5432",
+			wantErr:   false,
+		},
+		{
+			name:      "mongodb replica set",
+			dsnToTest: "mongodb://h1,h2,h3/db?replicaSet=rs0",
+			want:      []dsn.HostPort{{Host: "h1"}, {Host: "h2"}, {Host: "h3"}},
+			wantHost:  "h1",
+			wantPort:  "",
+			wantErr:   false,
+		},
+		{
+			name:      "mixed ports",
+			dsnToTest: "mongodb://h1,h2:27018/db",
+			want:      []dsn.HostPort{{Host: "h1"}, {Host: "h2", Port: "27018"}},
+			wantHost:  "h1",
+			wantPort:  "",
+			wantErr:   false,
+		},
+		{
+			name:      "invalid port in list",
+			dsnToTest: "postgres://h1:5432,h2:abc/db",
+			wantErr:   true,
+		},
+		{
+			name:      "bare host with port",
+			dsnToTest: "localhost:3307",
+			want:      []dsn.HostPort{{Host: "localhost", Port: "3307"}},
+			wantHost:  "localhost",
+			wantPort:  "3307",
+			wantErr:   false,
+		},
+		{
+			name:      "bare host with invalid port",
+			dsnToTest: "localhost:abc",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(), wantErr %v, got %v", tt.wantErr, err)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.Hosts(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DSN.Hosts() = %v, want %v", got, tt.want)
+			}
+			if got := d.GetHost(); got != tt.wantHost {
+				t.Errorf("DSN.GetHost() = %v, want %v", got, tt.wantHost)
+			}
+			if got := d.GetPort(""); got != tt.wantPort {
+				t.Errorf("DSN.GetPort() = %v, want %v", got, tt.wantPort)
+			}
+			if got := d.String(); got != tt.dsnToTest {
+				t.Errorf("DSN.String() = %v, want %v", got, tt.dsnToTest)
+			}
+			if got := d.Encode(); got != tt.dsnToTest {
+				t.Errorf("DSN.Encode() = %v, want %v", got, tt.dsnToTest)
+			}
+		})
+	}
+}
+
+func TestDSN_GetPostgresURIMultiHost(t *testing.T) {
+	d, err := dsn.New("postgres://u:p@h1:5432,h2,h3:5434/db?sslmode=require")
+	if err != nil {
+		t.Fatalf("Failed to create DSN: %v", err)
+	}
+	want := "host=h1,h2,h3 port=5432,5432,5434 user=u password=p dbname=db sslmode=require"
+	if got := d.GetPostgresURI(); got != want {
+		t.Errorf("DSN.GetPostgresURI() = %v, want %v", got, want)
+	}
+}
+
+func TestDSN_SetHosts(t *testing.T) {
+	base, err := dsn.New("postgres://u:p@h1:5432/db")
+	if err != nil {
+		t.Fatalf("Failed to create DSN: %v", err)
+	}
+	hosts := []dsn.HostPort{{Host: "h1", Port: "5432"}, {Host: "h2", Port: "5433"}}
+	d := base.WithHosts(hosts)
+	hosts[0].Host = "modified"
+
+	want := "postgres://u:p@h1:5432,h2:5433/db"
+	if got := d.String(); got != want {
+		t.Errorf("DSN.String() = %v, want %v", got, want)
+	}
+	if got := base.String(); got != "postgres://u:p@h1:5432/db" {
+		t.Errorf("base DSN.String() = %v, want unchanged", got)
+	}
+
+	d.SetPort("6000")
+	want = "postgres://u:p@h1:6000,h2:5433/db"
+	if got := d.String(); got != want {
+		t.Errorf("DSN.String() = %v, want %v", got, want)
+	}
+}
+
+func TestDSN_IPv6(t *testing.T) {
+	tests := []struct {
+		name         string
+		dsnToTest    string
+		wantHost     string
+		wantPort     string
+		wantEncode   string
+		wantRedacted string
+		wantPostgres string
+		wantErr      bool
+	}{
+		{
+			name:         "loopback with port",
+			dsnToTest:    "postgres://u:p@[::1]:5432/db",
+			wantHost:     "::1",
+			wantPort:     "5432",
+			wantEncode:   "postgres://u:p@[::1]:5432/db",
+			wantRedacted: "postgres://u:****@[::1]:5432/db",
+			wantPostgres: "host=::1 port=5432 user=u password=p dbname=db",
+			wantErr:      false,
+		},
+		{
+			name:         "without port",
+			dsnToTest:    "postgres://u:p@[2001:db8::10]/db",
+			wantHost:     "2001:db8::10",
+			wantPort:     "",
+			wantEncode:   "postgres://u:p@[2001:db8::10]/db",
+			wantRedacted: "postgres://u:****@[2001:db8::10]/db",
+			wantPostgres: "host=2001:db8::10 port=5432 user=u password=p dbname=db",
+			wantErr:      false,
+		},
+		{
+			name:         "zone identifier",
+			dsnToTest:    "postgres://u:p@[fe80::1%25eth0]:5432/db",
+			wantHost:     "fe80::1%eth0",
+			wantPort:     "5432",
+			wantEncode:   "postgres://u:p@[fe80::1%25eth0]:5432/db",
+			wantRedacted: "postgres://u:****@[fe80::1%25eth0]:5432/db",
+			wantPostgres: "host=fe80::1%eth0 port=5432 user=u password=p dbname=db",
+			wantErr:      false,
+		},
+		{
+			name:         "multi-host",
+			dsnToTest:    "postgres://u:p@[::1]:5432,[::2]:5433/db",
+			wantHost:     "::1",
+			wantPort:     "5432",
+			wantEncode:   "postgres://u:p@[::1]:5432,[::2]:5433/db",
+			wantRedacted: "postgres://u:****@[::1]:5432,[::2]:5433/db",
+			wantPostgres: "host=::1,::2 port=5432,5433 user=u password=p dbname=db",
+			wantErr:      false,
+		},
+		{
+			name:         "bare address",
+			dsnToTest:    "[::1]:5432",
+			wantHost:     "::1",
+			wantPort:     "5432",
+			wantEncode:   "[::1]:5432",
+			wantRedacted: "[::1]:5432",
+			wantPostgres: "host=::1 port=5432",
+			wantErr:      false,
+		},
+		{
+			name:      "missing bracket",
+			dsnToTest: "postgres://u:p@[::1:5432/db",
+			wantErr:   true,
+		},
+		{
+			name:      "not an IPv6 address",
+			dsnToTest: "postgres://u:p@[host]:5432/db",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(), wantErr %v, got %v", tt.wantErr, err)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.GetHost(); got != tt.wantHost {
+				t.Errorf("DSN.GetHost() = %v, want %v", got, tt.wantHost)
+			}
+			if got := d.GetPort(""); got != tt.wantPort {
+				t.Errorf("DSN.GetPort() = %v, want %v", got, tt.wantPort)
+			}
+			if got := d.Encode(); got != tt.wantEncode {
+				t.Errorf("DSN.Encode() = %v, want %v", got, tt.wantEncode)
+			}
+			if got := d.StringRedacted(); got != tt.wantRedacted {
+				t.Errorf("DSN.StringRedacted() = %v, want %v", got, tt.wantRedacted)
+			}
+			if got := d.GetPostgresURI(); got != tt.wantPostgres {
+				t.Errorf("DSN.GetPostgresURI() = %v, want %v", got, tt.wantPostgres)
+			}
+		})
+	}
+}
+
+func TestHostPort_String(t *testing.T) {
+	tests := []struct {
+		hp   dsn.HostPort
+		want string
+	}{
+		{hp: dsn.HostPort{Host: "host", Port: "5432"}, want: "host:5432"},
+		{hp: dsn.HostPort{Host: "host"}, want: "host"},
+		{hp: dsn.HostPort{Host: "::1", Port: "5432"}, want: "[::1]:5432"},
+		{hp: dsn.HostPort{Host: "fe80::1%eth0"}, want: "[fe80::1%eth0]"},
+		{hp: dsn.HostPort{Host: "not:ipv6", Port: "1"}, want: "not:ipv6:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.hp.String(); got != tt.want {
+				t.Errorf("HostPort.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var dataSourceName string
+var (
+	dataSourceName string
+	expandVars     bool
+)
 
 // getCmd represents the get command.
 var getCmd = &cobra.Command{
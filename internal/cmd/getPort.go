@@ -15,6 +15,6 @@ var getPort = &cobra.Command{
 	Long:  `get port of data source name`,
 	Run: func(_ *cobra.Command, _ []string) {
 		d := initDsnOrExit(dataSourceName)
-		fmt.Println(d.GetPort(defaultPort))
+		fmt.Println(portOf(d))
 	},
 }
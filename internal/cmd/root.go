@@ -29,20 +29,24 @@ func init() {
 	rootCmd.AddCommand(getCmd)
 
 	getCmd.PersistentFlags().StringVar(&dataSourceName, "d", "", "data source name")
+	getCmd.PersistentFlags().BoolVar(&expandVars, "expand", false,
+		"substitute ${VAR}, ${VAR:-default} and ${VAR:?error} with environment variables")
 
 	getCmd.AddCommand(getScheme)
 	getCmd.AddCommand(getDBName)
 	getCmd.AddCommand(getHost)
 
-	getPort.PersistentFlags().StringVar(&defaultPort, "p", "", 
-	"default port to print in case of no port in data source name")
+	getPort.PersistentFlags().StringVar(&defaultPort, "p", "",
+		"default port to print in case of no port in data source name (default: port of the scheme)")
 	getCmd.AddCommand(getPort)
 	getCmd.AddCommand(getUser)
 	getCmd.AddCommand(getPassword)
 
 	setEnvCmd.PersistentFlags().StringVar(&dataSourceName, "d", "", "data source name")
-	setEnvCmd.PersistentFlags().StringVar(&defaultPort, "p", "", 
-	"default port to print in case of no port in data source name")
+	setEnvCmd.PersistentFlags().BoolVar(&expandVars, "expand", false,
+		"substitute ${VAR}, ${VAR:-default} and ${VAR:?error} with environment variables")
+	setEnvCmd.PersistentFlags().StringVar(&defaultPort, "p", "",
+		"default port to print in case of no port in data source name (default: port of the scheme)")
 	setEnvCmd.PersistentFlags().StringVar(&prefixVar, "pr", "", "prefix for environment variable")
 	rootCmd.AddCommand(setEnvCmd)
 }
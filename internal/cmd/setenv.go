@@ -18,7 +18,7 @@ var setEnvCmd = &cobra.Command{
 		PrintVar("SCHEME", d.GetScheme())
 		PrintVar("DBNAME", d.GetDBName())
 		PrintVar("HOST", d.GetHost())
-		PrintVar("PORT", d.GetPort(defaultPort))
+		PrintVar("PORT", portOf(d))
 		PrintVar("USER", d.GetUser())
 		PrintVar("PASSWORD", d.GetPassword())
 	},
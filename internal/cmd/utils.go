@@ -14,9 +14,23 @@ func initDsnOrExit(dataSourceName string) *dsn.DSN {
 		fmt.Println("data source name is empty")
 		os.Exit(1)
 	}
-	d, err := dsn.New(dataSourceName)
+	var opts []dsn.ParseOption
+	if expandVars {
+		opts = append(opts, dsn.ExpandVariables(os.LookupEnv))
+	}
+	d, err := dsn.New(dataSourceName, opts...)
 	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	return d
 }
+
+// portOf returns the port of the DSN, the port given with --p if not specified,
+// or the default port of the scheme if --p is absent.
+func portOf(d *dsn.DSN) string {
+	if defaultPort != "" {
+		return d.GetPort(defaultPort)
+	}
+	return d.EffectivePort()
+}
@@ -0,0 +1,211 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsupportedScheme is returned when a DSN cannot be converted to another format for its scheme.
+var ErrUnsupportedScheme = errors.New("unsupported scheme")
+
+// jdbcPrefix is the prefix of JDBC URLs.
+const jdbcPrefix = "jdbc:"
+
+// isJDBCDSN reports whether dsn is a JDBC URL.
+func isJDBCDSN(dsn string) bool {
+	return len(dsn) >= len(jdbcPrefix) && strings.EqualFold(dsn[:len(jdbcPrefix)], jdbcPrefix)
+}
+
+// parseJDBC parses a JDBC URL:
+// jdbc:postgresql://host:5432/db?user=u&password=p,
+// jdbc:mysql://host:3306/db?user=u&password=p,
+// jdbc:sqlserver://host:1433;databaseName=db;user=u;password=p,
+// jdbc:oracle:thin:user/password@//host:1521/service.
+// The user and password parameters are moved to the user and password of the DSN.
+func parseJDBC(dsn string) (*DSN, error) {
+	rest := dsn[len(jdbcPrefix):]
+	lowerRest := strings.ToLower(rest)
+
+	var d *DSN
+	var err error
+	switch {
+	case strings.HasPrefix(lowerRest, "oracle:thin:"):
+		d, err = parseOracle(rest[len("oracle:thin:"):])
+	case strings.HasPrefix(lowerRest, "sqlserver://"):
+		d, err = parseJDBCSQLServer(rest[len("sqlserver://"):])
+	case strings.Contains(rest, "://"):
+		d, err = New(rest)
+	default:
+		return nil, fmt.Errorf("%w: unsupported JDBC URL", ErrInvalidDSNFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.dsn = dsn
+
+	for _, key := range []string{"user", "userName"} {
+		if user, ok := d.parameters.get(key); ok {
+			d.user = user
+			d.parameters = d.parameters.del(key)
+		}
+	}
+	if password, ok := d.parameters.get("password"); ok {
+		d.password = password
+		d.parameters = d.parameters.del("password")
+	}
+	return d, nil
+}
+
+// parseJDBCSQLServer parses the part of a SQL Server JDBC URL after jdbc:sqlserver://:
+// host[\instance][:port][;property=value[;property=value]].
+func parseJDBCSQLServer(s string) (*DSN, error) {
+	d := DSN{
+		scheme:     "sqlserver",
+		parameters: parameters{},
+	}
+
+	server, properties, _ := strings.Cut(s, ";")
+	if server != "" {
+		hostport, instance, _ := strings.Cut(server, `\`)
+		if i := strings.LastIndex(instance, ":"); i >= 0 {
+			instance, hostport = instance[:i], hostport+instance[i:]
+		}
+		h, err := parseHostPort(hostport)
+		if err != nil {
+			return nil, err
+		}
+		d.hosts = []HostPort{h}
+		d.instance = instance
+	}
+
+	// Properties follow the ADO.NET syntax, values may be enclosed in braces
+	for {
+		properties = strings.TrimLeft(properties, "; ")
+		if properties == "" {
+			break
+		}
+		key, rest, found := strings.Cut(properties, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: missing \"=\" in %q", ErrInvalidDSNFormat, properties)
+		}
+		key = strings.TrimSpace(key)
+		value, rest, err := readADOValue(rest)
+		if err != nil {
+			return nil, err
+		}
+		properties = rest
+		switch strings.ToLower(key) {
+		case "databasename", "database":
+			d.dbname = value
+		case "instancename":
+			d.instance = value
+		default:
+			d.parameters = d.parameters.add(key, value)
+		}
+	}
+	return &d, nil
+}
+
+// ToJDBC returns the DSN as a JDBC URL, with the user and password passed as parameters.
+// Supported schemes are postgres (pg, postgresql), mysql, mariadb, sqlserver (mssql) and oracle.
+func (d *DSN) ToJDBC() (string, error) {
+	switch d.scheme {
+	case "postgres", "postgresql", "pg":
+		return d.toJDBCURL("postgresql"), nil
+	case "mysql", "mariadb":
+		return d.toJDBCURL(d.scheme), nil
+	case "sqlserver", "mssql":
+		return d.toJDBCSQLServer(), nil
+	case "oracle":
+		return d.toJDBCOracle(), nil
+	default:
+		return "", fmt.Errorf("%w: %q cannot be converted to JDBC", ErrUnsupportedScheme, d.scheme)
+	}
+}
+
+// toJDBCURL returns jdbc:<subprotocol>://<hosts>/<dbname>?user=<user>&password=<password>&<parameters>.
+func (d *DSN) toJDBCURL(subprotocol string) string {
+	var result strings.Builder
+
+	result.WriteString(jdbcPrefix)
+	result.WriteString(subprotocol)
+	result.WriteString("://")
+	result.WriteString(encodeHosts(d.hosts))
+	result.WriteString("/")
+	result.WriteString(url.PathEscape(d.dbname))
+
+	params := d.parameters.clone()
+	if d.password != "" {
+		params = append(parameters{{key: "password", value: d.password}}, params...)
+	}
+	if d.user != "" {
+		params = append(parameters{{key: "user", value: d.user}}, params...)
+	}
+	if len(params) > 0 {
+		result.WriteString("?")
+		result.WriteString(params.encode())
+	}
+
+	return result.String()
+}
+
+// toJDBCOracle returns jdbc:oracle:thin:<user>/<password>@//<host>:<port>/<service_name>.
+func (d *DSN) toJDBCOracle() string {
+	var result strings.Builder
+
+	result.WriteString(jdbcPrefix)
+	result.WriteString("oracle:thin:")
+	if d.user != "" {
+		result.WriteString(d.user)
+		if d.password != "" {
+			result.WriteString(`/"`)
+			result.WriteString(d.password)
+			result.WriteString(`"`)
+		}
+	}
+	result.WriteString("@//")
+	result.WriteString(d.firstHost().String())
+	if d.dbname != "" {
+		result.WriteString("/")
+		result.WriteString(d.dbname)
+	}
+
+	return result.String()
+}
+
+// toJDBCSQLServer returns jdbc:sqlserver://<host>\<instance>:<port>;databaseName=<dbname>;user=<user>;password=<password>.
+func (d *DSN) toJDBCSQLServer() string {
+	var result strings.Builder
+
+	result.WriteString(jdbcPrefix)
+	result.WriteString("sqlserver://")
+	h := d.firstHost()
+	result.WriteString(HostPort{Host: h.Host}.String())
+	if d.instance != "" {
+		result.WriteString(`\`)
+		result.WriteString(d.instance)
+	}
+	if h.Port != "" {
+		result.WriteString(":")
+		result.WriteString(h.Port)
+	}
+
+	properties := parameters{
+		{key: "databaseName", value: d.dbname},
+		{key: "user", value: d.user},
+		{key: "password", value: d.password},
+	}
+	for _, property := range append(properties, d.parameters...) {
+		if property.value == "" {
+			continue
+		}
+		result.WriteString(";")
+		result.WriteString(property.key)
+		result.WriteString("=")
+		result.WriteString(quoteADOValue(property.value))
+	}
+
+	return result.String()
+}
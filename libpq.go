@@ -0,0 +1,150 @@
+package dsn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isLibpqDSN reports whether dsn uses the libpq keyword/value format:
+// host=localhost port=5432 dbname=mydb.
+func isLibpqDSN(dsn string) bool {
+	if strings.Contains(dsn, "://") {
+		return false
+	}
+	s := strings.TrimLeftFunc(dsn, isSpace)
+	end := strings.IndexFunc(s, func(r rune) bool { return !isKeywordChar(r) })
+	if end <= 0 {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimLeftFunc(s[end:], isSpace), "=")
+}
+
+// isSpace reports whether r is an ASCII white space, as isspace does for libpq.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\v' || r == '\f'
+}
+
+// isKeywordChar reports whether r can be part of a libpq keyword.
+func isKeywordChar(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+// parseLibpq parses a DSN in the libpq keyword/value format:
+// host=localhost port=5432 user=app password='p w' dbname=mydb sslmode=require.
+// Values may be single-quoted, and backslash escapes \' and \\.
+// Several hosts and ports can be given as comma-separated lists.
+// Unknown keywords are kept as parameters. The scheme is set to postgres.
+//
+//nolint:cyclop
+func parseLibpq(dsn string) (*DSN, error) {
+	d := DSN{
+		dsn:        dsn,
+		scheme:     "postgres",
+		parameters: parameters{},
+	}
+	var hostList, portList []string
+
+	s := dsn
+	for {
+		s = strings.TrimLeftFunc(s, isSpace)
+		if s == "" {
+			break
+		}
+
+		// Extract keyword
+		end := strings.IndexFunc(s, func(r rune) bool { return r == '=' || isSpace(r) })
+		if end < 0 {
+			return nil, fmt.Errorf("%w: missing \"=\" after %q", ErrInvalidDSNFormat, s)
+		}
+		key := s[:end]
+		s = strings.TrimLeftFunc(s[end:], isSpace)
+		if !strings.HasPrefix(s, "=") {
+			return nil, fmt.Errorf("%w: missing \"=\" after %q", ErrInvalidDSNFormat, key)
+		}
+		s = strings.TrimLeftFunc(s[1:], isSpace)
+
+		// Extract value
+		value, rest, err := readLibpqValue(s)
+		if err != nil {
+			return nil, err
+		}
+		s = rest
+
+		switch key {
+		case "host":
+			hostList = strings.Split(value, ",")
+		case "port":
+			portList = strings.Split(value, ",")
+		case "user":
+			d.user = value
+		case "password":
+			d.password = value
+		case "dbname":
+			d.dbname = value
+		default:
+			d.parameters = d.parameters.add(key, value)
+		}
+	}
+
+	if len(portList) > 1 && len(portList) != len(hostList) {
+		return nil, fmt.Errorf("%w: %d ports for %d hosts", ErrInvalidDSNFormat, len(portList), len(hostList))
+	}
+	for _, port := range portList {
+		if !isValidPort(port) {
+			return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidDSNFormat, port)
+		}
+	}
+	for i, host := range hostList {
+		h := HostPort{Host: host}
+		switch {
+		case len(portList) == 1:
+			h.Port = portList[0]
+		case len(portList) > 1:
+			h.Port = portList[i]
+		}
+		d.hosts = append(d.hosts, h)
+	}
+	if len(hostList) == 0 && len(portList) == 1 {
+		d.hosts = []HostPort{{Port: portList[0]}}
+	}
+
+	return &d, nil
+}
+
+// quoteLibpqValue quotes a value for the libpq keyword/value format if it is empty
+// or contains white spaces, quotes or backslashes.
+func quoteLibpqValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\v\f'\\") {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+// readLibpqValue reads a value, quoted or not, at the beginning of s.
+// It returns the unescaped value and the rest of s.
+func readLibpqValue(s string) (string, string, error) {
+	var value strings.Builder
+	quoted := strings.HasPrefix(s, "'")
+	if quoted {
+		s = s[1:]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			value.WriteByte(s[i])
+		case quoted && c == '\'':
+			return value.String(), s[i+1:], nil
+		case !quoted && isSpace(rune(c)):
+			return value.String(), s[i:], nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	if quoted {
+		return "", "", fmt.Errorf("%w: unterminated quoted string", ErrInvalidDSNFormat)
+	}
+	return value.String(), "", nil
+}
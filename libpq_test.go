@@ -0,0 +1,197 @@
+package dsn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+func TestNew_Libpq(t *testing.T) {
+	tests := []struct {
+		name         string
+		dsnToTest    string
+		wantUser     string
+		wantPassword string
+		wantHosts    []dsn.HostPort
+		wantDBName   string
+		wantParams   map[string]string
+		wantErr      bool
+	}{
+		{
+			name:         "complete",
+			dsnToTest:    "host=db port=5432 user=app password='p w' dbname=x sslmode=require",
+			wantUser:     "app",
+			wantPassword: "p w",
+			wantHosts:    []dsn.HostPort{{Host: "db", Port: "5432"}},
+			wantDBName:   "x",
+			wantParams:   map[string]string{"sslmode": "require"},
+			wantErr:      false,
+		},
+		{
+			name:         "escapes and spaces around =",
+			dsnToTest:    `  host = db  password='it\'s a \\ test' user=a\ b application_name=''  `,
+			wantUser:     "a b",
+			wantPassword: `it's a \ test`,
+			wantHosts:    []dsn.HostPort{{Host: "db"}},
+			wantDBName:   "",
+			wantParams:   map[string]string{"application_name": ""},
+			wantErr:      false,
+		},
+		{
+			name:       "multiple hosts",
+			dsnToTest:  "host=h1,h2 port=5432,5433 dbname=x target_session_attrs=read-write",
+			wantHosts:  []dsn.HostPort{{Host: "h1", Port: "5432"}, {Host: "h2", Port: "5433"}},
+			wantDBName: "x",
+			wantParams: map[string]string{"target_session_attrs": "read-write"},
+			wantErr:    false,
+		},
+		{
+			name:       "single port for multiple hosts",
+			dsnToTest:  "host=h1,h2 port=5433",
+			wantHosts:  []dsn.HostPort{{Host: "h1", Port: "5433"}, {Host: "h2", Port: "5433"}},
+			wantParams: map[string]string{},
+			wantErr:    false,
+		},
+		{
+			name:       "unix socket",
+			dsnToTest:  "host=/var/run/postgresql dbname=x",
+			wantHosts:  []dsn.HostPort{{Host: "/var/run/postgresql"}},
+			wantDBName: "x",
+			wantParams: map[string]string{},
+			wantErr:    false,
+		},
+		{
+			name:       "unknown keywords are kept",
+			dsnToTest:  "dbname=x connect_timeout=10 options='-c geqo=off'",
+			wantHosts:  nil,
+			wantDBName: "x",
+			wantParams: map[string]string{"connect_timeout": "10", "options": "-c geqo=off"},
+			wantErr:    false,
+		},
+		{
+			name:         "user first and password with a semicolon",
+			dsnToTest:    "user=app password=p;w host=h dbname=x",
+			wantUser:     "app",
+			wantPassword: "p;w",
+			wantHosts:    []dsn.HostPort{{Host: "h"}},
+			wantDBName:   "x",
+			wantParams:   map[string]string{},
+			wantErr:      false,
+		},
+		{
+			name:      "unterminated quote",
+			dsnToTest: "host=db password='secret",
+			wantErr:   true,
+		},
+		{
+			name:      "missing =",
+			dsnToTest: "host=db dbname",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid port",
+			dsnToTest: "host=db port=abc",
+			wantErr:   true,
+		},
+		{
+			name:      "ports mismatch",
+			dsnToTest: "host=h1,h2,h3 port=1,2",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(), wantErr %v, got %v", tt.wantErr, err)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.GetScheme(); got != "postgres" {
+				t.Errorf("DSN.GetScheme() = %v, want %v", got, "postgres")
+			}
+			if got := d.GetUser(); got != tt.wantUser {
+				t.Errorf("DSN.GetUser() = %v, want %v", got, tt.wantUser)
+			}
+			if got := d.GetPassword(); got != tt.wantPassword {
+				t.Errorf("DSN.GetPassword() = %v, want %v", got, tt.wantPassword)
+			}
+			if got := d.Hosts(); !reflect.DeepEqual(got, tt.wantHosts) {
+				t.Errorf("DSN.Hosts() = %v, want %v", got, tt.wantHosts)
+			}
+			if got := d.GetDBName(); got != tt.wantDBName {
+				t.Errorf("DSN.GetDBName() = %v, want %v", got, tt.wantDBName)
+			}
+			if got := d.GetParameters(); !reflect.DeepEqual(got, tt.wantParams) {
+				t.Errorf("DSN.GetParameters() = %v, want %v", got, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestNew_LibpqSameAsURL(t *testing.T) {
+	fromURL, err := dsn.New("postgres://app:p%20w@db:5432/x?sslmode=require")
+	if err != nil {
+		t.Fatalf("Failed to create DSN: %v", err)
+	}
+	fromLibpq, err := dsn.New("host=db port=5432 user=app password='p w' dbname=x sslmode=require")
+	if err != nil {
+		t.Fatalf("Failed to create DSN: %v", err)
+	}
+	if got, want := fromLibpq.Encode(), fromURL.Encode(); got != want {
+		t.Errorf("DSN.Encode() = %v, want %v", got, want)
+	}
+}
+
+func TestDSN_GetPostgresURIEscaping(t *testing.T) {
+	tests := []struct {
+		name      string
+		dsnToTest string
+		want      string
+	}{
+		{
+			name:      "password with space and quote",
+			dsnToTest: "postgres://app:it's%20a%20p%5Cw@db/x",
+			want:      `host=db port=5432 user=app password='it\'s a p\\w' dbname=x`,
+		},
+		{
+			name:      "no sslmode",
+			dsnToTest: "postgres://app@db/x",
+			want:      "host=db port=5432 user=app dbname=x",
+		},
+		{
+			name:      "all parameters",
+			dsnToTest: "postgres://app@db/x?connect_timeout=10&application_name=my%20app&sslmode=require&search_path=s",
+			want:      "host=db port=5432 user=app dbname=x connect_timeout=10 application_name='my app' sslmode=require search_path=s",
+		},
+		{
+			name:      "empty parameter",
+			dsnToTest: "postgres://db/x?options=",
+			want:      "host=db port=5432 dbname=x options=''",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got := d.GetPostgresURI()
+			if got != tt.want {
+				t.Errorf("DSN.GetPostgresURI() = %v, want %v", got, tt.want)
+			}
+
+			// The result must be read back with the same components
+			back, err := dsn.New(got)
+			if err != nil {
+				t.Fatalf("New(DSN.GetPostgresURI()) error = %v", err)
+			}
+			if back.GetPassword() != d.GetPassword() || !reflect.DeepEqual(back.GetParameters(), d.GetParameters()) {
+				t.Errorf("New(DSN.GetPostgresURI()) = %+v, want components of %+v", back, d)
+			}
+		})
+	}
+}
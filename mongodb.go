@@ -0,0 +1,145 @@
+package dsn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSRVRecord is returned when the DNS records of a mongodb+srv DSN are invalid.
+var ErrInvalidSRVRecord = errors.New("invalid SRV record")
+
+// mongoSRVScheme is the scheme of MongoDB DSNs resolved through DNS SRV records.
+const mongoSRVScheme = "mongodb+srv"
+
+// mongoTXTOptions are the only options a TXT record of a mongodb+srv DSN may hold.
+var mongoTXTOptions = []string{"authSource", "replicaSet", "loadBalanced"}
+
+// Resolver looks up the DNS records needed to resolve a mongodb+srv DSN.
+// *net.Resolver implements it.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// IsSRV reports whether the DSN must be resolved through DNS SRV records (mongodb+srv scheme).
+func (d *DSN) IsSRV() bool {
+	return d.CanonicalScheme() == "mongodb" && d.DriverSuffix() == "srv"
+}
+
+// validateSRV checks that a mongodb+srv DSN has a single host without port.
+func (d *DSN) validateSRV() error {
+	if err := d.srvFieldError(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDSNFormat, err.Reason)
+	}
+	return nil
+}
+
+// srvFieldError returns the error found by validateSRV as a FieldError, or nil.
+func (d *DSN) srvFieldError() *FieldError {
+	if !d.IsSRV() {
+		return nil
+	}
+	if len(d.hosts) != 1 || d.hosts[0].Host == "" {
+		return &FieldError{Field: "host", Value: encodeHosts(d.hosts), Reason: mongoSRVScheme + " requires exactly one host"}
+	}
+	if d.hosts[0].Port != "" {
+		return &FieldError{Field: "port", Value: d.hosts[0].Port, Reason: mongoSRVScheme + " must not include a port"}
+	}
+	return nil
+}
+
+// Resolve expands a mongodb+srv DSN into a mongodb DSN:
+// the hosts come from the SRV records of _<srvServiceName>._tcp.<host>, and the options
+// of the TXT record of <host> are added unless they are already set in the DSN.
+// TLS is enabled unless the tls or ssl parameter is set.
+// If r is nil, net.DefaultResolver is used. A DSN which is not mongodb+srv is returned as a copy.
+//
+//nolint:cyclop
+func (d *DSN) Resolve(ctx context.Context, r Resolver) (*DSN, error) {
+	if !d.IsSRV() {
+		return d.Clone(), nil
+	}
+	if err := d.validateSRV(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = net.DefaultResolver
+	}
+
+	resolved := d.Clone()
+	host := d.GetHost()
+
+	// Hosts from the SRV records
+	service := d.GetParameter("srvServiceName")
+	if service == "" {
+		service = "mongodb"
+	}
+	_, records, err := r.LookupSRV(ctx, service, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("lookup SRV records of %s: %w", host, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no SRV record for %s", ErrInvalidSRVRecord, host)
+	}
+	hosts := make([]HostPort, 0, len(records))
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		if !isSRVTargetInDomain(target, host) {
+			return nil, fmt.Errorf("%w: %s is not in the domain of %s", ErrInvalidSRVRecord, target, host)
+		}
+		hosts = append(hosts, HostPort{Host: target, Port: strconv.Itoa(int(record.Port))})
+	}
+	if maxHosts, err := strconv.Atoi(d.GetParameter("srvMaxHosts")); err == nil && maxHosts > 0 && maxHosts < len(hosts) {
+		hosts = hosts[:maxHosts]
+	}
+	resolved.hosts = hosts
+
+	// Options from the TXT record
+	txts, err := r.LookupTXT(ctx, host)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return nil, fmt.Errorf("lookup TXT records of %s: %w", host, err)
+	}
+	if len(txts) > 1 {
+		return nil, fmt.Errorf("%w: %d TXT records for %s", ErrInvalidSRVRecord, len(txts), host)
+	}
+	if len(txts) == 1 {
+		options, err := parseParameters(txts[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSRVRecord, err.Error())
+		}
+		for _, option := range options {
+			if !isOneOf(option.key, mongoTXTOptions) {
+				return nil, fmt.Errorf("%w: option %q is not allowed in TXT record", ErrInvalidSRVRecord, option.key)
+			}
+			if _, exists := resolved.parameters.get(option.key); !exists {
+				resolved.parameters = resolved.parameters.add(option.key, option.value)
+			}
+		}
+	}
+
+	_, hasTLS := resolved.parameters.get("tls")
+	_, hasSSL := resolved.parameters.get("ssl")
+	if !hasTLS && !hasSSL {
+		resolved.parameters = resolved.parameters.add("tls", "true")
+	}
+	resolved.parameters = resolved.parameters.del("srvServiceName").del("srvMaxHosts")
+	resolved.scheme, resolved.suffix = "mongodb", ""
+	resolved.modified = true
+	return resolved, nil
+}
+
+// isSRVTargetInDomain reports whether an SRV target belongs to the domain of host,
+// which is host without its first label when it has at least three labels.
+func isSRVTargetInDomain(target, host string) bool {
+	target, host = strings.ToLower(target), strings.ToLower(host)
+	if strings.Count(host, ".") < 2 {
+		return target == host || strings.HasSuffix(target, "."+host)
+	}
+	_, domain, _ := strings.Cut(host, ".")
+	return strings.HasSuffix(target, "."+domain)
+}
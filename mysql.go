@@ -0,0 +1,153 @@
+package dsn
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// mysqlNetworks are the protocols accepted by the go-sql-driver/mysql DSN format.
+var mysqlNetworks = []string{"tcp", "tcp4", "tcp6", "unix"}
+
+// isMySQLDSN reports whether dsn uses the go-sql-driver/mysql format:
+// [user[:password]@][net[(addr)]]/dbname[?params].
+// Only the forms which cannot be mistaken for a URL without scheme are recognised:
+// a protocol with an address in parentheses, or after an @, an address in parentheses,
+// a bare protocol or an empty address.
+func isMySQLDSN(dsn string) bool {
+	if strings.Contains(dsn, "://") {
+		return false
+	}
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return false
+	}
+	prefix := dsn[:slash]
+	at := strings.LastIndex(prefix, "@")
+	address := prefix[at+1:]
+	network, _, hasParens := strings.Cut(address, "(")
+	if at < 0 {
+		return hasParens && strings.HasSuffix(address, ")") && isMySQLNetwork(network)
+	}
+	if address == "" {
+		return true
+	}
+	if hasParens {
+		return strings.HasSuffix(address, ")") && (network == "" || isMySQLNetwork(network))
+	}
+	return isMySQLNetwork(address)
+}
+
+// isMySQLNetwork reports whether network is a protocol of the go-sql-driver/mysql format.
+func isMySQLNetwork(network string) bool {
+	for _, n := range mysqlNetworks {
+		if network == n {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMySQL parses a DSN in the go-sql-driver/mysql format:
+// [user[:password]@][net[(addr)]]/dbname[?params].
+// As with the driver, the user and password are not percent-decoded, and the
+// dbname starts after the last /. The scheme is set to mysql.
+func parseMySQL(dsn string) (*DSN, error) {
+	if strings.IndexFunc(dsn, isControl) >= 0 {
+		return nil, fmt.Errorf("%w: invalid control character", ErrInvalidDSNFormat)
+	}
+
+	slash := strings.LastIndex(dsn, "/")
+	prefix, rest := dsn[:slash], dsn[slash+1:]
+
+	// Extract user and password
+	user, password, address := "", "", prefix
+	if at := strings.LastIndex(prefix, "@"); at >= 0 {
+		user, password, _ = strings.Cut(prefix[:at], ":")
+		address = prefix[at+1:]
+	}
+
+	// Extract protocol and address
+	network, addr, _ := strings.Cut(address, "(")
+	addr = strings.TrimSuffix(addr, ")")
+	var hosts []HostPort
+	if addr != "" {
+		h := HostPort{Host: addr}
+		if network != "unix" {
+			var err error
+			if h, err = parseHostPort(addr); err != nil {
+				return nil, err
+			}
+		}
+		hosts = []HostPort{h}
+	}
+
+	// Extract dbname and parameters
+	rawDBName, rawQuery, _ := strings.Cut(rest, "?")
+	dbname, err := unescape(rawDBName)
+	if err != nil {
+		return nil, err
+	}
+	params, err := parseParameters(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	d := DSN{
+		dsn:        dsn,
+		scheme:     "mysql",
+		user:       user,
+		password:   password,
+		hosts:      hosts,
+		dbname:     dbname,
+		parameters: params,
+	}
+	return &d, nil
+}
+
+// GetMySQLDSN returns a go-sql-driver/mysql connection string:
+// <user>:<password>@tcp(<host>:<port>)/<dbname>?<parameters>.
+// The port defaults to 3306, and a Unix domain socket is written as unix(<path>),
+// without the parameter which held it.
+// For a multi-host DSN, only the first host is used.
+func (d *DSN) GetMySQLDSN() string {
+	var result strings.Builder
+
+	if d.user != "" || d.password != "" {
+		result.WriteString(d.user)
+		if d.password != "" {
+			result.WriteString(":")
+			result.WriteString(d.password)
+		}
+		result.WriteString("@")
+	}
+
+	socket := d.SocketPath()
+	switch h := d.firstHost(); {
+	case socket != "":
+		result.WriteString("unix(")
+		result.WriteString(socket)
+		result.WriteString(")")
+	case h.Host != "":
+		result.WriteString("tcp(")
+		result.WriteString(HostPort{Host: h.Host, Port: h.portOrDefault(defaultPortOf("mysql"))}.String())
+		result.WriteString(")")
+	}
+
+	result.WriteString("/")
+	result.WriteString(url.PathEscape(d.dbname))
+
+	params := make(parameters, 0, len(d.parameters))
+	for _, param := range d.parameters {
+		if param.value == socket && isOneOf(param.key, socketParameters) {
+			continue
+		}
+		params = append(params, param)
+	}
+	if len(params) > 0 {
+		result.WriteString("?")
+		result.WriteString(params.encode())
+	}
+
+	return result.String()
+}
@@ -0,0 +1,188 @@
+package dsn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseOption configures how New parses a DSN.
+// Without options, New accepts every supported format as is.
+type ParseOption func(*parseOptions)
+
+// parseOptions holds the configuration set by the options given to New.
+type parseOptions struct {
+	normalizeScheme  bool
+	requireScheme    bool
+	allowedSchemes   []string
+	strictParameters bool
+	disallowBareHost bool
+	defaultScheme    string
+	defaultPort      string
+	defaultParams    map[string]string
+	expand           bool
+	lookup           LookupFunc
+}
+
+// NormalizeScheme makes New replace a scheme alias with the name of its registered driver,
+// keeping the driver suffix of SQLAlchemy-style schemes:
+// pg:// and postgresql:// become postgres://, postgresql+psycopg2:// becomes postgres+psycopg2://.
+// GetScheme then returns the driver name, and DriverSuffix the suffix.
+// A file: SQLite URI keeps its form.
+func NormalizeScheme() ParseOption {
+	return func(o *parseOptions) {
+		o.normalizeScheme = true
+	}
+}
+
+// RequireScheme makes New reject a DSN without scheme, such as a bare hostname.
+func RequireScheme() ParseOption {
+	return func(o *parseOptions) {
+		o.requireScheme = true
+	}
+}
+
+// AllowedSchemes makes New reject a DSN whose scheme is not one of schemes.
+// A scheme is also allowed if its registered driver is named in schemes:
+// AllowedSchemes("postgres") accepts pg:// and postgresql://.
+func AllowedSchemes(schemes ...string) ParseOption {
+	return func(o *parseOptions) {
+		o.allowedSchemes = append(o.allowedSchemes, schemes...)
+	}
+}
+
+// StrictParameters makes New reject a parameter unknown to the driver registered for the scheme.
+// A DSN with parameters and a scheme without registered driver is rejected too.
+func StrictParameters() ParseOption {
+	return func(o *parseOptions) {
+		o.strictParameters = true
+	}
+}
+
+// DisallowBareHost makes New reject a bare hostname such as localhost, which is otherwise
+// accepted as a DSN with a host only.
+func DisallowBareHost() ParseOption {
+	return func(o *parseOptions) {
+		o.disallowBareHost = true
+	}
+}
+
+// DefaultScheme sets the scheme of a DSN given without scheme.
+func DefaultScheme(scheme string) ParseOption {
+	return func(o *parseOptions) {
+		o.defaultScheme = strings.ToLower(scheme)
+	}
+}
+
+// Defaults sets the port of the hosts given without port, and the parameters which are not set.
+// An empty port leaves the hosts unchanged.
+func Defaults(port string, parameters map[string]string) ParseOption {
+	return func(o *parseOptions) {
+		o.defaultPort = port
+		if o.defaultParams == nil {
+			o.defaultParams = make(map[string]string, len(parameters))
+		}
+		for key, value := range parameters {
+			o.defaultParams[key] = value
+		}
+	}
+}
+
+// ExpandVariables makes New substitute the ${VAR}, ${VAR:-default} and ${VAR:?message} variables
+// of the DSN with the values returned by lookup (os.LookupEnv if nil) before parsing it, see Expand.
+func ExpandVariables(lookup LookupFunc) ParseOption {
+	return func(o *parseOptions) {
+		o.expand = true
+		o.lookup = lookup
+	}
+}
+
+// apply applies the defaults, then the checks of the options to the DSN parsed from dsn.
+// A bare hostname is rejected before the defaults give it a scheme.
+func (o *parseOptions) apply(d *DSN, dsn string) error {
+	if o.disallowBareHost && d.scheme == "" && isBareHost(dsn) {
+		return fmt.Errorf("%w: bare hostname %q not allowed", ErrInvalidDSNFormat, dsn)
+	}
+
+	if err := o.applyDefaults(d); err != nil {
+		return err
+	}
+	if o.normalizeScheme {
+		d.normalizeScheme()
+	}
+
+	if o.requireScheme && d.scheme == "" {
+		return fmt.Errorf("%w: missing scheme", ErrInvalidDSNFormat)
+	}
+	if len(o.allowedSchemes) > 0 && !d.isSchemeAllowed(o.allowedSchemes) {
+		return fmt.Errorf("%w: scheme %q not allowed", ErrUnsupportedScheme, d.scheme)
+	}
+	if o.strictParameters {
+		return d.checkParameters()
+	}
+	return nil
+}
+
+// applyDefaults sets the default scheme, port and parameters of the options on the DSN.
+// The default scheme is handled as if it had been parsed: the path of a SQL Server DSN
+// becomes its instance, and a mongodb+srv DSN is validated before any default port.
+func (o *parseOptions) applyDefaults(d *DSN) error {
+	if o.defaultScheme != "" && d.scheme == "" {
+		d.scheme = o.defaultScheme
+		d.modified = true
+		if isSQLServerScheme(d.CanonicalScheme()) {
+			d.fromSQLServerURL()
+		}
+		if err := d.validateSRV(); err != nil {
+			return err
+		}
+	}
+	if o.defaultPort != "" && !d.IsSRV() {
+		for i, h := range d.hosts {
+			if h.Port == "" && h.Host != "" && !strings.HasPrefix(h.Host, "/") {
+				d.hosts[i].Port = o.defaultPort
+				d.modified = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(o.defaultParams))
+	for key := range o.defaultParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := d.parameters.get(key); !ok {
+			d.parameters = d.parameters.add(key, o.defaultParams[key])
+			d.modified = true
+		}
+	}
+	return nil
+}
+
+// isSchemeAllowed reports whether the scheme of the DSN, or the name of its driver, is one of schemes.
+func (d *DSN) isSchemeAllowed(schemes []string) bool {
+	driver, hasDriver := d.Driver()
+	for _, scheme := range schemes {
+		if strings.EqualFold(scheme, d.scheme) || (hasDriver && strings.EqualFold(scheme, driver.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkParameters returns an error for the first parameter unknown to the driver of the DSN.
+func (d *DSN) checkParameters() error {
+	if len(d.parameters) == 0 {
+		return nil
+	}
+	driver, ok := d.Driver()
+	if !ok {
+		return fmt.Errorf("%w: no driver registered for %q to check parameters", ErrUnsupportedScheme, d.scheme)
+	}
+	for _, p := range d.parameters {
+		if _, known := lookupParameter(driver, p.key); !known {
+			return fmt.Errorf("%w: unknown parameter %q for scheme %q", ErrInvalidParameters, p.key, d.scheme)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package dsn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+func TestNew_ParseOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		dsnToTest  string
+		opts       []dsn.ParseOption
+		wantString string
+		wantErr    error
+	}{
+		{
+			name:       "no options",
+			dsnToTest:  "localhost",
+			wantString: "localhost",
+		},
+		{
+			name:      "require scheme",
+			dsnToTest: "user@host/db",
+			opts:      []dsn.ParseOption{dsn.RequireScheme()},
+			wantErr:   dsn.ErrInvalidDSNFormat,
+		},
+		{
+			name:       "require scheme with default scheme",
+			dsnToTest:  "user@host/db",
+			opts:       []dsn.ParseOption{dsn.RequireScheme(), dsn.DefaultScheme("postgres")},
+			wantString: "postgres://user@host/db",
+		},
+		{
+			name:       "default scheme does not replace a scheme",
+			dsnToTest:  "mysql://user@host/db",
+			opts:       []dsn.ParseOption{dsn.DefaultScheme("postgres")},
+			wantString: "mysql://user@host/db",
+		},
+		{
+			name:       "sqlserver default scheme",
+			dsnToTest:  "user@host/inst?database=db",
+			opts:       []dsn.ParseOption{dsn.DefaultScheme("sqlserver")},
+			wantString: "sqlserver://user@host/inst?database=db",
+		},
+		{
+			name:      "mongodb+srv default scheme with a port",
+			dsnToTest: "cluster.example.com:27017/db",
+			opts:      []dsn.ParseOption{dsn.DefaultScheme("mongodb+srv")},
+			wantErr:   dsn.ErrInvalidDSNFormat,
+		},
+		{
+			name:       "mongodb+srv default scheme without default port",
+			dsnToTest:  "cluster.example.com/db",
+			opts:       []dsn.ParseOption{dsn.DefaultScheme("mongodb+srv"), dsn.Defaults("27017", nil)},
+			wantString: "mongodb+srv://cluster.example.com/db",
+		},
+		{
+			name:       "allowed scheme",
+			dsnToTest:  "postgres://host/db",
+			opts:       []dsn.ParseOption{dsn.AllowedSchemes("postgres", "mysql")},
+			wantString: "postgres://host/db",
+		},
+		{
+			name:       "allowed scheme through its driver",
+			dsnToTest:  "pg://host/db",
+			opts:       []dsn.ParseOption{dsn.AllowedSchemes("postgres")},
+			wantString: "pg://host/db",
+		},
+		{
+			name:      "scheme not allowed",
+			dsnToTest: "redis://host/0",
+			opts:      []dsn.ParseOption{dsn.AllowedSchemes("postgres", "mysql")},
+			wantErr:   dsn.ErrUnsupportedScheme,
+		},
+		{
+			name:       "strict parameters",
+			dsnToTest:  "postgres://host/db?sslmode=require&connect_timeout=10",
+			opts:       []dsn.ParseOption{dsn.StrictParameters()},
+			wantString: "postgres://host/db?sslmode=require&connect_timeout=10",
+		},
+		{
+			name:      "strict parameters with unknown parameter",
+			dsnToTest: "postgres://host/db?sslmod=require",
+			opts:      []dsn.ParseOption{dsn.StrictParameters()},
+			wantErr:   dsn.ErrInvalidParameters,
+		},
+		{
+			name:       "strict parameters are case-insensitive for sqlserver",
+			dsnToTest:  "Server=host;Database=db;Encrypt=true",
+			opts:       []dsn.ParseOption{dsn.StrictParameters()},
+			wantString: "Server=host;Database=db;Encrypt=true",
+		},
+		{
+			name:       "strict parameters are case-insensitive for mongodb",
+			dsnToTest:  "mongodb://h/db?replicaset=rs0&readpreference=secondary",
+			opts:       []dsn.ParseOption{dsn.StrictParameters()},
+			wantString: "mongodb://h/db?replicaset=rs0&readpreference=secondary",
+		},
+		{
+			name:      "strict parameters without driver",
+			dsnToTest: "foo://host/db?a=b",
+			opts:      []dsn.ParseOption{dsn.StrictParameters()},
+			wantErr:   dsn.ErrUnsupportedScheme,
+		},
+		{
+			name:      "bare host disallowed",
+			dsnToTest: "localhost",
+			opts:      []dsn.ParseOption{dsn.DisallowBareHost()},
+			wantErr:   dsn.ErrInvalidDSNFormat,
+		},
+		{
+			name:      "bare host disallowed despite a default scheme",
+			dsnToTest: "localhost",
+			opts:      []dsn.ParseOption{dsn.DisallowBareHost(), dsn.DefaultScheme("postgres")},
+			wantErr:   dsn.ErrInvalidDSNFormat,
+		},
+		{
+			name:       "host with a scheme when bare host disallowed",
+			dsnToTest:  "postgres://localhost",
+			opts:       []dsn.ParseOption{dsn.DisallowBareHost()},
+			wantString: "postgres://localhost",
+		},
+		{
+			name:       "defaults",
+			dsnToTest:  "postgres://h1,h2:5433/db?sslmode=disable",
+			opts:       []dsn.ParseOption{dsn.Defaults("5432", map[string]string{"sslmode": "require", "connect_timeout": "5"})},
+			wantString: "postgres://h1:5432,h2:5433/db?sslmode=disable&connect_timeout=5",
+		},
+		{
+			name:       "defaults do not touch a socket",
+			dsnToTest:  "postgres://%2Fvar%2Frun%2Fpostgresql/db",
+			opts:       []dsn.ParseOption{dsn.Defaults("5432", nil)},
+			wantString: "postgres://%2Fvar%2Frun%2Fpostgresql/db",
+		},
+		{
+			name:       "defaults with normalized scheme",
+			dsnToTest:  "pg://host/db",
+			opts:       []dsn.ParseOption{dsn.NormalizeScheme(), dsn.Defaults("5432", nil)},
+			wantString: "postgres://host:5432/db",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if d != nil {
+					t.Errorf("expected d to be nil")
+				}
+				return
+			}
+			if got := d.String(); got != tt.wantString {
+				t.Errorf("DSN.String() = %v, want %v", got, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestNew_DefaultSchemeSQLServer(t *testing.T) {
+	d, err := dsn.New("host/inst?database=db", dsn.DefaultScheme("sqlserver"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := d.GetDBName(); got != "db" {
+		t.Errorf("DSN.GetDBName() = %v, want %v", got, "db")
+	}
+	if got := d.GetInstance(); got != "inst" {
+		t.Errorf("DSN.GetInstance() = %v, want %v", got, "inst")
+	}
+}
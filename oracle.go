@@ -0,0 +1,175 @@
+package dsn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tnsNode is a node of an Oracle TNS descriptor: (KEY=value) or (KEY=(...)(...)).
+type tnsNode struct {
+	key      string
+	value    string
+	children []tnsNode
+}
+
+// isOracleDSN reports whether dsn uses an Oracle specific format:
+// a TNS descriptor (DESCRIPTION=...), an EZConnect string starting with //,
+// or credentials written as user/password@ before the parameters.
+func isOracleDSN(dsn string) bool {
+	if strings.Contains(dsn, "://") {
+		return false
+	}
+	s := strings.TrimLeftFunc(dsn, isSpace)
+	if strings.HasPrefix(s, "(") || (strings.HasPrefix(s, "//") && !strings.HasPrefix(s, "///")) {
+		return true
+	}
+	at := oracleCredentialsEnd(s)
+	if at < 0 {
+		return false
+	}
+	slash := strings.IndexByte(s, '/')
+	return slash >= 0 && slash < at && !strings.ContainsAny(s[:slash], "@:")
+}
+
+// oracleCredentialsEnd returns the index of the @ ending the credentials of s, or -1.
+// The parameters are ignored, as their values may hold an @.
+func oracleCredentialsEnd(s string) int {
+	s, _, _ = strings.Cut(s, "?")
+	return strings.LastIndex(s, "@")
+}
+
+// parseOracle parses a DSN in an Oracle specific format:
+// [user/password@][//]host[:port][/service_name][?parameters],
+// [user/password@]host:port:SID,
+// [user/password@](DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s))).
+// The service name (or SID) is the dbname. The scheme is set to oracle.
+func parseOracle(dsn string) (*DSN, error) {
+	s := strings.TrimSpace(dsn)
+	user, password := "", ""
+	if at := oracleCredentialsEnd(s); at >= 0 && !strings.HasPrefix(s, "(") {
+		user, password, _ = strings.Cut(s[:at], "/")
+		password = strings.Trim(password, `"`)
+		s = s[at+1:]
+	}
+
+	var d *DSN
+	var err error
+	switch {
+	case strings.HasPrefix(s, "("):
+		d, err = parseTNSDescriptor(s)
+	case strings.Count(s, ":") == 2 && !strings.ContainsAny(s, "/[?"):
+		// host:port:SID
+		i := strings.LastIndex(s, ":")
+		d, err = parseURL(s[:i])
+		if err == nil {
+			d.dbname = s[i+1:]
+		}
+	default:
+		d, err = parseURL(strings.TrimPrefix(s, "//"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.dsn = dsn
+	d.scheme = "oracle"
+	d.user = user
+	d.password = password
+	return d, nil
+}
+
+// parseTNSDescriptor parses a TNS descriptor. All the ADDRESS nodes become hosts,
+// the SERVICE_NAME or SID becomes the dbname, and the simple attributes of the
+// DESCRIPTION are kept as parameters.
+func parseTNSDescriptor(s string) (*DSN, error) {
+	root, rest, err := parseTNSNode(s)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("%w: unexpected %q after descriptor", ErrInvalidDSNFormat, rest)
+	}
+	if !strings.EqualFold(root.key, "DESCRIPTION") {
+		return nil, fmt.Errorf("%w: expected DESCRIPTION, got %q", ErrInvalidDSNFormat, root.key)
+	}
+
+	d := DSN{parameters: parameters{}}
+	for _, address := range root.findAll("ADDRESS") {
+		port := address.get("PORT")
+		if !isValidPort(port) {
+			return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidDSNFormat, port)
+		}
+		d.hosts = append(d.hosts, HostPort{Host: address.get("HOST"), Port: port})
+	}
+	for _, connectData := range root.findAll("CONNECT_DATA") {
+		d.dbname = connectData.get("SERVICE_NAME")
+		if d.dbname == "" {
+			d.dbname = connectData.get("SID")
+		}
+	}
+	for _, child := range root.children {
+		if child.children == nil {
+			d.parameters = d.parameters.add(strings.ToLower(child.key), child.value)
+		}
+	}
+	return &d, nil
+}
+
+// parseTNSNode parses a node starting with ( and returns the rest of s.
+func parseTNSNode(s string) (tnsNode, string, error) {
+	s = strings.TrimLeftFunc(s, isSpace)
+	if !strings.HasPrefix(s, "(") {
+		return tnsNode{}, "", fmt.Errorf("%w: expected \"(\" in descriptor", ErrInvalidDSNFormat)
+	}
+	key, rest, found := strings.Cut(s[1:], "=")
+	if !found || strings.ContainsAny(key, "()") {
+		return tnsNode{}, "", fmt.Errorf("%w: missing \"=\" in descriptor", ErrInvalidDSNFormat)
+	}
+	node := tnsNode{key: strings.TrimSpace(key)}
+
+	rest = strings.TrimLeftFunc(rest, isSpace)
+	if !strings.HasPrefix(rest, "(") {
+		end := strings.IndexAny(rest, "()")
+		if end < 0 || rest[end] != ')' {
+			return tnsNode{}, "", fmt.Errorf("%w: missing \")\" in descriptor", ErrInvalidDSNFormat)
+		}
+		node.value = strings.TrimSpace(rest[:end])
+		return node, rest[end+1:], nil
+	}
+
+	node.children = []tnsNode{}
+	for {
+		rest = strings.TrimLeftFunc(rest, isSpace)
+		if strings.HasPrefix(rest, ")") {
+			return node, rest[1:], nil
+		}
+		child, childRest, err := parseTNSNode(rest)
+		if err != nil {
+			return tnsNode{}, "", err
+		}
+		node.children = append(node.children, child)
+		rest = childRest
+	}
+}
+
+// findAll returns the descendants of n whose key is key.
+func (n tnsNode) findAll(key string) []tnsNode {
+	var nodes []tnsNode
+	for _, child := range n.children {
+		if strings.EqualFold(child.key, key) {
+			nodes = append(nodes, child)
+		}
+		nodes = append(nodes, child.findAll(key)...)
+	}
+	return nodes
+}
+
+// get returns the value of the child of n whose key is key.
+func (n tnsNode) get(key string) string {
+	for _, child := range n.children {
+		if strings.EqualFold(child.key, key) {
+			return child.value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidParameterValue is returned when a parameter cannot be converted to the requested type.
+var ErrInvalidParameterValue = errors.New("invalid parameter value")
+
+// ParamType are the types a parameter can be converted to by Param.
+type ParamType interface {
+	string | int | int64 | float64 | bool | time.Duration
+}
+
+// Param returns the value of a parameter converted to T, and whether the parameter is set.
+// An unset parameter returns the zero value of T, false and no error.
+// A value which cannot be converted returns an error wrapping ErrInvalidParameterValue.
+// Booleans accept true/false, 1/0, yes/no and on/off; durations accept 10s as well as bare seconds.
+func Param[T ParamType](d *DSN, name string) (T, bool, error) {
+	var zero T
+	raw, ok := d.parameters.get(name)
+	if !ok {
+		return zero, false, nil
+	}
+
+	var value any
+	var err error
+	switch any(zero).(type) {
+	case string:
+		value = raw
+	case int:
+		value, err = strconv.Atoi(raw)
+	case int64:
+		value, err = strconv.ParseInt(raw, 10, 64)
+	case float64:
+		value, err = strconv.ParseFloat(raw, 64)
+	case bool:
+		value, err = parseBool(raw)
+	case time.Duration:
+		value, err = parseDuration(raw)
+	}
+	if err != nil {
+		return zero, true, fmt.Errorf("%w: %s=%q is not a valid %T", ErrInvalidParameterValue, name, raw, zero)
+	}
+	//nolint:forcetypeassert // value has the type of T, chosen by the switch above
+	return value.(T), true, nil
+}
+
+// GetParameterInt returns the value of a parameter as an integer, and whether the parameter is set.
+func (d *DSN) GetParameterInt(name string) (int, bool, error) {
+	return Param[int](d, name)
+}
+
+// GetParameterBool returns the value of a parameter as a boolean, and whether the parameter is set.
+// true, 1, yes and on are true; false, 0, no and off are false, whatever their case.
+func (d *DSN) GetParameterBool(name string) (bool, bool, error) {
+	return Param[bool](d, name)
+}
+
+// GetParameterDuration returns the value of a parameter as a duration, and whether the parameter is set.
+// The value is either a Go duration such as 10s or 1m30s, or a number of seconds such as 10.
+func (d *DSN) GetParameterDuration(name string) (time.Duration, bool, error) {
+	return Param[time.Duration](d, name)
+}
+
+// parseBool parses the boolean values accepted by database drivers.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes", "on", "t", "y":
+		return true, nil
+	case "false", "0", "no", "off", "f", "n":
+		return false, nil
+	}
+	return false, strconv.ErrSyntax
+}
+
+// parseDuration parses a Go duration, or a number of seconds.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
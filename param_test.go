@@ -0,0 +1,132 @@
+package dsn_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+func TestDSN_GetParameterInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   string
+		want    int
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "set", param: "pool_max_conns", want: 10, wantOK: true},
+		{name: "negative", param: "offset", want: -3, wantOK: true},
+		{name: "unset", param: "missing", want: 0, wantOK: false},
+		{name: "invalid", param: "sslmode", want: 0, wantOK: true, wantErr: true},
+	}
+	d, err := dsn.New("postgres://host/db?pool_max_conns=10&offset=-3&sslmode=require")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := d.GetParameterInt(tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DSN.GetParameterInt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, dsn.ErrInvalidParameterValue) {
+				t.Errorf("DSN.GetParameterInt() error = %v, want ErrInvalidParameterValue", err)
+			}
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("DSN.GetParameterInt() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDSN_GetParameterBool(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{value: "true", want: true},
+		{value: "1", want: true},
+		{value: "yes", want: true},
+		{value: "ON", want: true},
+		{value: "false", want: false},
+		{value: "0", want: false},
+		{value: "no", want: false},
+		{value: "off", want: false},
+		{value: "maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := dsn.New("mysql://host/db?parseTime=" + tt.value)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got, ok, err := d.GetParameterBool("parseTime")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DSN.GetParameterBool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || !ok {
+				t.Errorf("DSN.GetParameterBool() = %v, %v, want %v, true", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDSN_GetParameterDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "10", want: 10 * time.Second},
+		{value: "10s", want: 10 * time.Second},
+		{value: "1m30s", want: 90 * time.Second},
+		{value: "250ms", want: 250 * time.Millisecond},
+		{value: "ten", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := dsn.New("postgres://host/db?connect_timeout=" + tt.value)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got, ok, err := d.GetParameterDuration("connect_timeout")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DSN.GetParameterDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || !ok {
+				t.Errorf("DSN.GetParameterDuration() = %v, %v, want %v, true", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParam(t *testing.T) {
+	d, err := dsn.New("postgres://host/db?sslmode=require&ratio=0.5&size=9000000000&timeout=5")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got, ok, err := dsn.Param[string](d, "sslmode"); got != "require" || !ok || err != nil {
+		t.Errorf("Param[string]() = %v, %v, %v", got, ok, err)
+	}
+	if got, ok, err := dsn.Param[float64](d, "ratio"); got != 0.5 || !ok || err != nil {
+		t.Errorf("Param[float64]() = %v, %v, %v", got, ok, err)
+	}
+	if got, ok, err := dsn.Param[int64](d, "size"); got != 9000000000 || !ok || err != nil {
+		t.Errorf("Param[int64]() = %v, %v, %v", got, ok, err)
+	}
+	if got, ok, err := dsn.Param[time.Duration](d, "timeout"); got != 5*time.Second || !ok || err != nil {
+		t.Errorf("Param[time.Duration]() = %v, %v, %v", got, ok, err)
+	}
+	if got, ok, err := dsn.Param[int](d, "missing"); got != 0 || ok || err != nil {
+		t.Errorf("Param[int]() = %v, %v, %v", got, ok, err)
+	}
+	_, _, err = dsn.Param[bool](d, "sslmode")
+	want := `invalid parameter value: sslmode="require" is not a valid bool`
+	if err == nil || err.Error() != want {
+		t.Errorf("Param[bool]() error = %v, want %v", err, want)
+	}
+}
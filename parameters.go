@@ -0,0 +1,118 @@
+package dsn
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// parameter is a single key=value pair of a DSN query string.
+type parameter struct {
+	key   string
+	value string
+}
+
+// parameters is an ordered list of parameters, a key may appear several times.
+type parameters []parameter
+
+// parseParameters parses a raw query string, keeping the order and the repeated keys.
+func parseParameters(rawQuery string) (parameters, error) {
+	params := parameters{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidParameters, err.Error())
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidParameters, err.Error())
+		}
+		params = append(params, parameter{key: key, value: value})
+	}
+	return params, nil
+}
+
+// get returns the last value of key.
+func (p parameters) get(key string) (string, bool) {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].key == key {
+			return p[i].value, true
+		}
+	}
+	return "", false
+}
+
+// values returns all the values of key in order.
+func (p parameters) values(key string) []string {
+	var values []string
+	for _, param := range p {
+		if param.key == key {
+			values = append(values, param.value)
+		}
+	}
+	return values
+}
+
+// set replaces all the values of key by value.
+// The parameter keeps the position of its first occurrence, or is appended.
+func (p parameters) set(key, value string) parameters {
+	result := make(parameters, 0, len(p)+1)
+	found := false
+	for _, param := range p {
+		if param.key != key {
+			result = append(result, param)
+			continue
+		}
+		if !found {
+			result = append(result, parameter{key: key, value: value})
+			found = true
+		}
+	}
+	if !found {
+		result = append(result, parameter{key: key, value: value})
+	}
+	return result
+}
+
+// add appends a value to key, keeping the existing ones.
+func (p parameters) add(key, value string) parameters {
+	return append(p, parameter{key: key, value: value})
+}
+
+// del removes all the values of key.
+func (p parameters) del(key string) parameters {
+	result := make(parameters, 0, len(p))
+	for _, param := range p {
+		if param.key != key {
+			result = append(result, param)
+		}
+	}
+	return result
+}
+
+// clone returns a copy of the parameters.
+func (p parameters) clone() parameters {
+	return append(make(parameters, 0, len(p)), p...)
+}
+
+// toMap returns the parameters as a map, keeping the last value of each key.
+func (p parameters) toMap() map[string]string {
+	m := make(map[string]string, len(p))
+	for _, param := range p {
+		m[param.key] = param.value
+	}
+	return m
+}
+
+// encode returns the parameters as an escaped query string.
+func (p parameters) encode() string {
+	pairs := make([]string, 0, len(p))
+	for _, param := range p {
+		pairs = append(pairs, url.QueryEscape(param.key)+"="+url.QueryEscape(param.value))
+	}
+	return strings.Join(pairs, "&")
+}
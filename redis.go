@@ -0,0 +1,89 @@
+package dsn
+
+import (
+	"strconv"
+	"strings"
+)
+
+// redisSentinelSchemes are the schemes of Redis Sentinel DSNs:
+// redis+sentinel://:password@sentinel1:26379,sentinel2:26379/mymaster/0.
+var redisSentinelSchemes = []string{"redis+sentinel", "rediss+sentinel", "redis-sentinel", "sentinel"}
+
+// redisMasterParameters are the parameters which may hold the name of the Sentinel master.
+var redisMasterParameters = []string{"master_name", "masterName", "sentinelMasterId"}
+
+// defaultSentinelPort is the default port of Redis Sentinel.
+const defaultSentinelPort = "26379"
+
+// isRedisSentinel reports whether the DSN designates Redis Sentinels:
+// a sentinel scheme, or a master name given as parameter.
+func (d *DSN) isRedisSentinel() bool {
+	return isOneOf(d.fullScheme(), redisSentinelSchemes) || d.masterNameParameter() != ""
+}
+
+// masterNameParameter returns the name of the Sentinel master given as parameter.
+func (d *DSN) masterNameParameter() string {
+	for _, param := range redisMasterParameters {
+		if value := d.GetParameter(param); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// RedisDB returns the Redis database index: the path of redis://host:6379/2,
+// the path after the master name for Sentinel DSNs, or the db parameter.
+// It returns 0 if no index is given or if it is not a number.
+func (d *DSN) RedisDB() int {
+	db := d.dbname
+	if isOneOf(d.fullScheme(), redisSentinelSchemes) {
+		_, db, _ = strings.Cut(d.dbname, "/")
+	}
+	if db == "" {
+		db = d.GetParameter("db")
+	}
+	index, err := strconv.Atoi(db)
+	if err != nil || index < 0 {
+		return 0
+	}
+	return index
+}
+
+// UsesTLS reports whether the DSN requires TLS: rediss scheme,
+// or tls or ssl parameter set to true.
+func (d *DSN) UsesTLS() bool {
+	if scheme := d.fullScheme(); scheme == "rediss" || scheme == "rediss+sentinel" {
+		return true
+	}
+	for _, param := range []string{"tls", "ssl"} {
+		if enabled, err := strconv.ParseBool(d.GetParameter(param)); err == nil && enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// SentinelAddrs returns the addresses (host:port) of the Redis Sentinels, or nil if the
+// DSN does not designate Sentinels. The port defaults to 26379, and the addr parameters
+// are appended to the hosts.
+func (d *DSN) SentinelAddrs() []string {
+	if !d.isRedisSentinel() {
+		return nil
+	}
+	addrs := make([]string, 0, len(d.hosts))
+	for _, h := range d.hosts {
+		addrs = append(addrs, HostPort{Host: h.Host, Port: h.portOrDefault(defaultSentinelPort)}.String())
+	}
+	return append(addrs, d.GetParameterValues("addr")...)
+}
+
+// MasterName returns the name of the Redis Sentinel master: the first path segment
+// of a sentinel scheme DSN, or the master_name, masterName or sentinelMasterId parameter.
+func (d *DSN) MasterName() string {
+	if isOneOf(d.fullScheme(), redisSentinelSchemes) {
+		if name, _, _ := strings.Cut(d.dbname, "/"); name != "" {
+			return name
+		}
+	}
+	return d.masterNameParameter()
+}
@@ -0,0 +1,107 @@
+package dsn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+func TestDSN_Redis(t *testing.T) {
+	tests := []struct {
+		name           string
+		dsnToTest      string
+		wantDB         int
+		wantTLS        bool
+		wantSentinels  []string
+		wantMasterName string
+		wantDBName     string
+	}{
+		{
+			name:       "db index",
+			dsnToTest:  "redis://:pass@host:6379/2",
+			wantDB:     2,
+			wantDBName: "2",
+		},
+		{
+			name:       "no db index",
+			dsnToTest:  "redis://host:6379",
+			wantDB:     0,
+			wantDBName: "",
+		},
+		{
+			name:       "invalid db index",
+			dsnToTest:  "redis://host:6379/cache",
+			wantDB:     0,
+			wantDBName: "cache",
+		},
+		{
+			name:       "db parameter",
+			dsnToTest:  "redis://host:6379?db=3",
+			wantDB:     3,
+			wantDBName: "",
+		},
+		{
+			name:       "rediss",
+			dsnToTest:  "rediss://:pass@host:6380/1",
+			wantDB:     1,
+			wantTLS:    true,
+			wantDBName: "1",
+		},
+		{
+			name:       "tls parameter",
+			dsnToTest:  "redis://host:6379/0?tls=true",
+			wantDB:     0,
+			wantTLS:    true,
+			wantDBName: "0",
+		},
+		{
+			name:           "sentinel",
+			dsnToTest:      "redis+sentinel://:pass@s1:26379,s2:26380,s3/mymaster/4",
+			wantDB:         4,
+			wantSentinels:  []string{"s1:26379", "s2:26380", "s3:26379"},
+			wantMasterName: "mymaster",
+			wantDBName:     "mymaster/4",
+		},
+		{
+			name:           "sentinel with TLS and master name parameter",
+			dsnToTest:      "rediss+sentinel://s1?master_name=mymaster&db=2",
+			wantDB:         2,
+			wantTLS:        true,
+			wantSentinels:  []string{"s1:26379"},
+			wantMasterName: "mymaster",
+			wantDBName:     "",
+		},
+		{
+			name:           "go-redis failover URL",
+			dsnToTest:      "redis://:pass@s1:26379/1?master_name=mymaster&addr=s2:26379&addr=s3:26379",
+			wantDB:         1,
+			wantSentinels:  []string{"s1:26379", "s2:26379", "s3:26379"},
+			wantMasterName: "mymaster",
+			wantDBName:     "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := d.RedisDB(); got != tt.wantDB {
+				t.Errorf("DSN.RedisDB() = %v, want %v", got, tt.wantDB)
+			}
+			if got := d.UsesTLS(); got != tt.wantTLS {
+				t.Errorf("DSN.UsesTLS() = %v, want %v", got, tt.wantTLS)
+			}
+			if got := d.SentinelAddrs(); !reflect.DeepEqual(got, tt.wantSentinels) {
+				t.Errorf("DSN.SentinelAddrs() = %v, want %v", got, tt.wantSentinels)
+			}
+			if got := d.MasterName(); got != tt.wantMasterName {
+				t.Errorf("DSN.MasterName() = %v, want %v", got, tt.wantMasterName)
+			}
+			if got := d.GetDBName(); got != tt.wantDBName {
+				t.Errorf("DSN.GetDBName() = %v, want %v", got, tt.wantDBName)
+			}
+		})
+	}
+}
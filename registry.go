@@ -0,0 +1,163 @@
+package dsn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Driver describes a database engine: the schemes designating it, its default port,
+// the parameters it knows, how to validate a DSN and how to serialize it in the native
+// format of the engine. Drivers are made available with Register.
+type Driver interface {
+	// Name returns the canonical scheme of the engine, e.g. postgres.
+	Name() string
+	// Aliases returns the other schemes designating the engine, e.g. pg and postgresql.
+	Aliases() []string
+	// DefaultPort returns the default port of the engine, or an empty string if it has none.
+	DefaultPort() string
+	// Parameters returns the parameters known by the engine.
+	Parameters() []ParameterSpec
+	// Validate checks that the DSN follows the rules of the engine.
+	Validate(d *DSN) error
+	// Format returns the DSN in the native format of the engine.
+	Format(d *DSN) (string, error)
+}
+
+// ParameterType is the type of the value of a parameter.
+type ParameterType int
+
+// Parameter types, checked by Validate.
+const (
+	// ParameterString accepts any value.
+	ParameterString ParameterType = iota
+	// ParameterInt accepts an integer.
+	ParameterInt
+	// ParameterBool accepts true/false, 1/0, yes/no and on/off.
+	ParameterBool
+	// ParameterDuration accepts a Go duration such as 10s, or a number of seconds.
+	ParameterDuration
+	// ParameterStrictDuration accepts a Go duration such as 10s only, as time.ParseDuration.
+	ParameterStrictDuration
+)
+
+// String returns the name of the type.
+func (t ParameterType) String() string {
+	switch t {
+	case ParameterInt:
+		return "integer"
+	case ParameterBool:
+		return "boolean"
+	case ParameterDuration:
+		return "duration"
+	case ParameterStrictDuration:
+		return "duration with a unit"
+	default:
+		return "string"
+	}
+}
+
+// ParameterSpec describes a parameter known by a driver.
+type ParameterSpec struct {
+	Name string
+	// Type is the type of the value.
+	Type ParameterType
+	// Allowed restricts the value to a list, if not empty.
+	Allowed []string
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+// Register makes a driver available for its name and aliases.
+// As with database/sql, it panics if the driver is nil or if one of its schemes is already registered.
+func Register(driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if driver == nil {
+		panic("dsn: Register driver is nil")
+	}
+	schemes := append([]string{driver.Name()}, driver.Aliases()...)
+	for _, scheme := range schemes {
+		if _, dup := drivers[strings.ToLower(scheme)]; dup {
+			panic("dsn: Register called twice for scheme " + scheme)
+		}
+	}
+	for _, scheme := range schemes {
+		drivers[strings.ToLower(scheme)] = driver
+	}
+}
+
+// Lookup returns the driver registered for a scheme or one of its aliases.
+// A SQLAlchemy-style scheme dialect+driver is looked up by its dialect if it is not registered itself.
+func Lookup(scheme string) (Driver, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	scheme = strings.ToLower(scheme)
+	if driver, ok := drivers[scheme]; ok {
+		return driver, true
+	}
+	if dialect, _, found := strings.Cut(scheme, "+"); found {
+		driver, ok := drivers[dialect]
+		return driver, ok
+	}
+	return nil, false
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for scheme, driver := range drivers {
+		if scheme == strings.ToLower(driver.Name()) {
+			names = append(names, driver.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Driver returns the driver registered for the scheme of the DSN.
+func (d *DSN) Driver() (Driver, bool) {
+	return Lookup(d.scheme)
+}
+
+// Format returns the DSN in the native format of its driver,
+// e.g. the libpq keyword/value format for postgres.
+func (d *DSN) Format() (string, error) {
+	driver, ok := d.Driver()
+	if !ok {
+		return "", fmt.Errorf("%w: no driver registered for %q", ErrUnsupportedScheme, d.scheme)
+	}
+	return driver.Format(d)
+}
+
+// parameterFolder is implemented by the drivers whose parameter names are case-insensitive.
+type parameterFolder interface {
+	foldsParameters() bool
+}
+
+// lookupParameter returns the specification of a parameter known by the driver.
+func lookupParameter(driver Driver, name string) (ParameterSpec, bool) {
+	folder, ok := driver.(parameterFolder)
+	fold := ok && folder.foldsParameters()
+	for _, spec := range driver.Parameters() {
+		if spec.Name == name || (fold && strings.EqualFold(spec.Name, name)) {
+			return spec, true
+		}
+	}
+	return ParameterSpec{}, false
+}
+
+// defaultPortOf returns the default port of the driver registered for a scheme.
+func defaultPortOf(scheme string) string {
+	driver, ok := Lookup(scheme)
+	if !ok {
+		return ""
+	}
+	return driver.DefaultPort()
+}
@@ -0,0 +1,45 @@
+package dsn
+
+import "strings"
+
+// CanonicalScheme returns the name of the driver registered for the scheme of the DSN,
+// e.g. postgres for pg://, postgresql:// and postgresql+psycopg2://.
+// If no driver is registered, the lowercased scheme without its driver suffix is returned.
+func (d *DSN) CanonicalScheme() string {
+	if driver, ok := d.Driver(); ok {
+		return driver.Name()
+	}
+	dialect, _, _ := strings.Cut(strings.ToLower(d.scheme), "+")
+	return dialect
+}
+
+// DriverSuffix returns the driver part of a SQLAlchemy-style dialect+driver scheme,
+// e.g. psycopg2 for postgresql+psycopg2://, or an empty string.
+func (d *DSN) DriverSuffix() string {
+	if d.suffix != "" {
+		return d.suffix
+	}
+	_, suffix, _ := strings.Cut(d.scheme, "+")
+	return suffix
+}
+
+// fullScheme returns the scheme of the DSN followed by its driver suffix, as written in the URL.
+func (d *DSN) fullScheme() string {
+	if d.suffix == "" {
+		return d.scheme
+	}
+	return d.scheme + "+" + d.suffix
+}
+
+// normalizeScheme replaces the scheme of the DSN with its canonical form,
+// and moves the driver suffix to its own field.
+func (d *DSN) normalizeScheme() {
+	if d.scheme == "" {
+		return
+	}
+	scheme, suffix := d.CanonicalScheme(), d.DriverSuffix()
+	if scheme != d.scheme || suffix != d.suffix {
+		d.scheme, d.suffix = scheme, suffix
+		d.modified = true
+	}
+}
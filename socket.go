@@ -0,0 +1,28 @@
+package dsn
+
+import "strings"
+
+// socketParameters are the parameters which may hold the path of a Unix domain socket.
+var socketParameters = []string{"host", "socket", "unix_socket"}
+
+// IsUnixSocket reports whether the DSN connects through a Unix domain socket.
+func (d *DSN) IsUnixSocket() bool {
+	return d.SocketPath() != ""
+}
+
+// SocketPath returns the path of the Unix domain socket (or the directory holding it
+// for PostgreSQL), or an empty string if the DSN does not use a socket.
+// The socket can be given as a percent-encoded host (postgres://%2Fvar%2Frun%2Fpostgresql/db),
+// as unix(/tmp/mysql.sock), or in the host or socket parameter (postgres:///db?host=/var/run/postgresql).
+// A parameter holding an absolute path takes precedence over a network host, as with libpq.
+func (d *DSN) SocketPath() string {
+	if host := d.GetHost(); strings.HasPrefix(host, "/") {
+		return host
+	}
+	for _, param := range socketParameters {
+		if value := d.GetParameter(param); strings.HasPrefix(value, "/") {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package dsn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqliteMemory is the name of the SQLite in-memory database.
+const sqliteMemory = ":memory:"
+
+// sqliteSchemes are the schemes of SQLite DSNs whose dbname is a file path.
+var sqliteSchemes = []string{"sqlite", "sqlite3", "file"}
+
+// isSQLiteScheme reports whether scheme designates SQLite.
+func isSQLiteScheme(scheme string) bool {
+	return isOneOf(scheme, sqliteSchemes)
+}
+
+// isSQLiteDSN reports whether dsn is a SQLite DSN: :memory:, a file: URI,
+// or a sqlite:// or sqlite3:// URL.
+func isSQLiteDSN(dsn string) bool {
+	lower := strings.ToLower(dsn)
+	return dsn == sqliteMemory ||
+		strings.HasPrefix(lower, "file:") ||
+		strings.HasPrefix(lower, "sqlite://") ||
+		strings.HasPrefix(lower, "sqlite3://")
+}
+
+// parseSQLite parses a SQLite DSN, where the dbname is a file path, absolute or relative:
+// sqlite:///var/data/app.db, sqlite://data/app.db, file:test.db?cache=shared&mode=memory, :memory:.
+// A SQLAlchemy absolute path (sqlite:////var/data/app.db) is also accepted.
+// Query parameters are kept.
+func parseSQLite(dsn string) (*DSN, error) {
+	if dsn == sqliteMemory {
+		return &DSN{dsn: dsn, scheme: "sqlite", dbname: sqliteMemory, parameters: parameters{}}, nil
+	}
+	if strings.IndexFunc(dsn, isControl) >= 0 {
+		return nil, fmt.Errorf("%w: invalid control character", ErrInvalidDSNFormat)
+	}
+
+	scheme, rest, _ := strings.Cut(dsn, ":")
+	scheme = strings.ToLower(scheme)
+	rest, _, _ = strings.Cut(rest, "#")
+	rawPath, rawQuery, _ := strings.Cut(rest, "?")
+	rawPath = strings.TrimPrefix(rawPath, "//")
+	if strings.HasPrefix(rawPath, "//") {
+		rawPath = rawPath[1:]
+	}
+
+	path, err := unescape(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	params, err := parseParameters(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	d := DSN{
+		dsn:        dsn,
+		scheme:     scheme,
+		fileURI:    scheme == "file",
+		dbname:     path,
+		parameters: params,
+	}
+	return &d, nil
+}
+
+// encodeSQLite returns the SQLite DSN as <scheme>://<path>?<parameters>, or file:<path>?<parameters>
+// for a file: URI, even when its scheme has been normalized.
+func (d *DSN) encodeSQLite() string {
+	var result strings.Builder
+
+	if d.fileURI || d.scheme == "file" {
+		result.WriteString("file:")
+	} else {
+		result.WriteString(d.fullScheme())
+		result.WriteString("://")
+	}
+	result.WriteString(escapePath(d.dbname))
+
+	if len(d.parameters) > 0 {
+		result.WriteString("?")
+		result.WriteString(d.parameters.encode())
+	}
+
+	return result.String()
+}
@@ -0,0 +1,125 @@
+package dsn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+func TestNew_SQLite(t *testing.T) {
+	tests := []struct {
+		name       string
+		dsnToTest  string
+		wantScheme string
+		wantDBName string
+		wantParams map[string]string
+		wantEncode string
+		wantErr    bool
+	}{
+		{
+			name:       "absolute path",
+			dsnToTest:  "sqlite:///var/data/app.db",
+			wantScheme: "sqlite",
+			wantDBName: "/var/data/app.db",
+			wantParams: map[string]string{},
+			wantEncode: "sqlite:///var/data/app.db",
+			wantErr:    false,
+		},
+		{
+			name:       "SQLAlchemy absolute path",
+			dsnToTest:  "sqlite:////var/data/app.db",
+			wantScheme: "sqlite",
+			wantDBName: "/var/data/app.db",
+			wantParams: map[string]string{},
+			wantEncode: "sqlite:///var/data/app.db",
+			wantErr:    false,
+		},
+		{
+			name:       "relative path",
+			dsnToTest:  "sqlite3://data/app.db?_journal_mode=WAL&_busy_timeout=5000",
+			wantScheme: "sqlite3",
+			wantDBName: "data/app.db",
+			wantParams: map[string]string{"_journal_mode": "WAL", "_busy_timeout": "5000"},
+			wantEncode: "sqlite3://data/app.db?_journal_mode=WAL&_busy_timeout=5000",
+			wantErr:    false,
+		},
+		{
+			name:       "path with space",
+			dsnToTest:  "sqlite:///var/my%20data/app.db",
+			wantScheme: "sqlite",
+			wantDBName: "/var/my data/app.db",
+			wantParams: map[string]string{},
+			wantEncode: "sqlite:///var/my%20data/app.db",
+			wantErr:    false,
+		},
+		{
+			name:       "file URI",
+			dsnToTest:  "file:test.db?cache=shared&mode=memory",
+			wantScheme: "file",
+			wantDBName: "test.db",
+			wantParams: map[string]string{"cache": "shared", "mode": "memory"},
+			wantEncode: "file:test.db?cache=shared&mode=memory",
+			wantErr:    false,
+		},
+		{
+			name:       "file URI with absolute path",
+			dsnToTest:  "file:///var/data/app.db?mode=ro",
+			wantScheme: "file",
+			wantDBName: "/var/data/app.db",
+			wantParams: map[string]string{"mode": "ro"},
+			wantEncode: "file:/var/data/app.db?mode=ro",
+			wantErr:    false,
+		},
+		{
+			name:       "memory",
+			dsnToTest:  ":memory:",
+			wantScheme: "sqlite",
+			wantDBName: ":memory:",
+			wantParams: map[string]string{},
+			wantEncode: "sqlite://:memory:",
+			wantErr:    false,
+		},
+		{
+			name:       "shared memory",
+			dsnToTest:  "file::memory:?cache=shared",
+			wantScheme: "file",
+			wantDBName: ":memory:",
+			wantParams: map[string]string{"cache": "shared"},
+			wantEncode: "file::memory:?cache=shared",
+			wantErr:    false,
+		},
+		{
+			name:      "invalid escape",
+			dsnToTest: "sqlite:///var/%zz.db",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(), wantErr %v, got %v", tt.wantErr, err)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.GetScheme(); got != tt.wantScheme {
+				t.Errorf("DSN.GetScheme() = %v, want %v", got, tt.wantScheme)
+			}
+			if got := d.GetDBName(); got != tt.wantDBName {
+				t.Errorf("DSN.GetDBName() = %v, want %v", got, tt.wantDBName)
+			}
+			if got := d.GetHost(); got != "" {
+				t.Errorf("DSN.GetHost() = %v, want empty", got)
+			}
+			if got := d.GetParameters(); !reflect.DeepEqual(got, tt.wantParams) {
+				t.Errorf("DSN.GetParameters() = %v, want %v", got, tt.wantParams)
+			}
+			if got := d.Encode(); got != tt.wantEncode {
+				t.Errorf("DSN.Encode() = %v, want %v", got, tt.wantEncode)
+			}
+		})
+	}
+}
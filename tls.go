@@ -0,0 +1,283 @@
+package dsn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrTLSConfig is returned when the TLS parameters of a DSN cannot be turned into a *tls.Config.
+var ErrTLSConfig = errors.New("invalid TLS configuration")
+
+// TLSConfigurer is implemented by the drivers which know how to build a *tls.Config
+// from the parameters of a DSN.
+type TLSConfigurer interface {
+	// TLSConfig returns the TLS configuration of the DSN, or nil if TLS is disabled.
+	TLSConfig(d *DSN) (*tls.Config, error)
+}
+
+// tlsVersions maps the names of TLS versions used by the parameters to their crypto/tls value.
+var tlsVersions = map[string]uint16{
+	"TLSv1":   tls.VersionTLS10,
+	"TLSv1.0": tls.VersionTLS10,
+	"TLSv1.1": tls.VersionTLS11,
+	"TLSv1.2": tls.VersionTLS12,
+	"TLSv1.3": tls.VersionTLS13,
+	"1.0":     tls.VersionTLS10,
+	"1.1":     tls.VersionTLS11,
+	"1.2":     tls.VersionTLS12,
+	"1.3":     tls.VersionTLS13,
+}
+
+// TLSConfig returns the TLS configuration described by the parameters of the DSN, or nil if TLS
+// is disabled. The PEM files referenced by the parameters are loaded.
+// The driver registered for the scheme interprets the parameters if it implements TLSConfigurer:
+// sslmode, sslrootcert, sslcert and sslkey for postgres (with the libpq semantics),
+// tls for mysql, the rediss scheme for redis, tls and tlsCAFile for mongodb, encrypt for sqlserver.
+// Otherwise TLS is enabled by a tls or ssl parameter set to true.
+// The server name is the first host of the DSN.
+func (d *DSN) TLSConfig() (*tls.Config, error) {
+	if driver, ok := d.Driver(); ok {
+		if configurer, ok := driver.(TLSConfigurer); ok {
+			return configurer.TLSConfig(d)
+		}
+	}
+	return genericTLSConfig(d)
+}
+
+// genericTLSConfig returns a verifying TLS configuration if the DSN uses TLS, as reported by UsesTLS.
+func genericTLSConfig(d *DSN) (*tls.Config, error) {
+	if !d.UsesTLS() {
+		return nil, nil //nolint:nilnil // nil means TLS is disabled
+	}
+	return newTLSConfig(d), nil
+}
+
+// newTLSConfig returns a TLS configuration verifying the first host of the DSN.
+func newTLSConfig(d *DSN) *tls.Config {
+	return &tls.Config{
+		ServerName: d.GetHost(),
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// postgresTLSConfig returns the TLS configuration of a postgres DSN, following libpq:
+// disable turns TLS off, allow, prefer and require encrypt without checking the server certificate,
+// verify-ca checks the certificate chain and verify-full checks the host name too.
+// As with libpq, require checks the chain when sslrootcert is given, and sslrootcert=system
+// verifies the server against the system roots with verify-full, the default sslmode
+// in that case, any other sslmode being rejected.
+// verify-ca and verify-full without sslrootcert also use the system roots.
+func postgresTLSConfig(d *DSN) (*tls.Config, error) {
+	if d.IsUnixSocket() {
+		return nil, nil //nolint:nilnil // TLS is not used on Unix sockets
+	}
+	rootCert := d.GetParameter("sslrootcert")
+	sslmode, ok := d.parameters.get("sslmode")
+	switch {
+	case rootCert == "system" && !ok:
+		sslmode = "verify-full"
+	case rootCert == "system" && sslmode != "verify-full":
+		return nil, fmt.Errorf("%w: sslmode %q may not be used with sslrootcert=system", ErrTLSConfig, sslmode)
+	case !ok:
+		sslmode = "prefer"
+	}
+	if sslmode == "disable" {
+		return nil, nil //nolint:nilnil // nil means TLS is disabled
+	}
+	if !isOneOf(sslmode, postgresSSLModes) {
+		return nil, fmt.Errorf("%w: unknown sslmode %q", ErrTLSConfig, sslmode)
+	}
+
+	config := newTLSConfig(d)
+	if version := d.GetParameter("ssl_min_protocol_version"); version != "" {
+		minVersion, ok := tlsVersions[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown ssl_min_protocol_version %q", ErrTLSConfig, version)
+		}
+		config.MinVersion = minVersion
+	}
+	if err := loadKeyPair(config, d.GetParameter("sslcert"), d.GetParameter("sslkey")); err != nil {
+		return nil, err
+	}
+
+	if rootCert != "" && rootCert != "system" {
+		roots, err := loadCertPool(rootCert)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = roots
+	}
+
+	switch {
+	case sslmode == "verify-full":
+	case sslmode == "verify-ca" || (sslmode == "require" && rootCert != ""):
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = verifyChain(config.RootCAs)
+	default:
+		config.InsecureSkipVerify = true
+	}
+	return config, nil
+}
+
+// mysqlTLSConfig returns the TLS configuration of a mysql DSN, following the tls parameter of
+// go-sql-driver/mysql: true verifies the server, skip-verify and preferred do not.
+func mysqlTLSConfig(d *DSN) (*tls.Config, error) {
+	mode := d.GetParameter("tls")
+	if d.IsUnixSocket() || mode == "" || mode == "false" {
+		return nil, nil //nolint:nilnil // nil means TLS is disabled
+	}
+	config := newTLSConfig(d)
+	switch mode {
+	case "true":
+	case "skip-verify", "preferred":
+		config.InsecureSkipVerify = true
+	default:
+		return nil, fmt.Errorf("%w: unknown tls %q", ErrTLSConfig, mode)
+	}
+	return config, nil
+}
+
+// redisTLSConfig returns the TLS configuration of a Redis DSN, enabled by the rediss scheme
+// or the tls or ssl parameter. skip_verify=true does not verify the server.
+func redisTLSConfig(d *DSN) (*tls.Config, error) {
+	config, err := genericTLSConfig(d)
+	if config == nil || err != nil {
+		return config, err
+	}
+	if skip, _, _ := d.GetParameterBool("skip_verify"); skip {
+		config.InsecureSkipVerify = true
+	}
+	return config, nil
+}
+
+// mongoTLSConfig returns the TLS configuration of a MongoDB DSN, enabled by the tls or ssl parameter,
+// or by default for mongodb+srv. tlsCAFile holds the roots, tlsCertificateKeyFile the client
+// certificate and its key, and tlsInsecure or tlsAllowInvalidCertificates disable the verification.
+func mongoTLSConfig(d *DSN) (*tls.Config, error) {
+	enabled := d.IsSRV()
+	for _, param := range []string{"tls", "ssl"} {
+		if value, ok, _ := d.GetParameterBool(param); ok {
+			enabled = value
+		}
+	}
+	if !enabled {
+		return nil, nil //nolint:nilnil // nil means TLS is disabled
+	}
+
+	config := newTLSConfig(d)
+	if caFile := d.GetParameter("tlsCAFile"); caFile != "" {
+		roots, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = roots
+	}
+	keyFile := d.GetParameter("tlsCertificateKeyFile")
+	if err := loadKeyPair(config, keyFile, keyFile); err != nil {
+		return nil, err
+	}
+	for _, param := range []string{"tlsInsecure", "tlsAllowInvalidCertificates"} {
+		if insecure, _, _ := d.GetParameterBool(param); insecure {
+			config.InsecureSkipVerify = true
+		}
+	}
+	return config, nil
+}
+
+// sqlServerTLSConfig returns the TLS configuration of a SQL Server DSN, enabled by
+// encrypt set to true, yes, mandatory or strict. The certificate parameter holds the roots,
+// hostNameInCertificate the server name, and TrustServerCertificate disables the verification.
+func sqlServerTLSConfig(d *DSN) (*tls.Config, error) {
+	encrypt, _ := d.parameterFold("encrypt")
+	if !isOneOf(strings.ToLower(encrypt), []string{"true", "yes", "mandatory", "strict"}) {
+		return nil, nil //nolint:nilnil // nil means TLS is disabled
+	}
+
+	config := newTLSConfig(d)
+	if serverName, ok := d.parameterFold("hostNameInCertificate"); ok {
+		config.ServerName = serverName
+	}
+	if certificate, ok := d.parameterFold("certificate"); ok && certificate != "" {
+		roots, err := loadCertPool(certificate)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = roots
+	}
+	if trust, ok := d.parameterFold("TrustServerCertificate"); ok {
+		if insecure, err := parseBool(trust); err == nil && insecure {
+			config.InsecureSkipVerify = true
+		}
+	}
+	return config, nil
+}
+
+// parameterFold returns the last value of a parameter whose name matches key, ignoring case.
+func (d *DSN) parameterFold(key string) (string, bool) {
+	for i := len(d.parameters) - 1; i >= 0; i-- {
+		if strings.EqualFold(d.parameters[i].key, key) {
+			return d.parameters[i].value, true
+		}
+	}
+	return "", false
+}
+
+// loadCertPool returns a pool holding the certificates of a PEM file.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTLSConfig, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: no certificate found in %s", ErrTLSConfig, path)
+	}
+	return pool, nil
+}
+
+// loadKeyPair adds the client certificate of the PEM files to the configuration.
+// Nothing is loaded if certFile is empty.
+func loadKeyPair(config *tls.Config, certFile, keyFile string) error {
+	if certFile == "" {
+		return nil
+	}
+	if keyFile == "" {
+		return fmt.Errorf("%w: %s has no key", ErrTLSConfig, certFile)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTLSConfig, err)
+	}
+	config.Certificates = append(config.Certificates, cert)
+	return nil
+}
+
+// verifyChain returns a function checking that the server certificate chains to roots
+// (the system roots if nil), without checking the host name.
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("%w: no server certificate", ErrTLSConfig)
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrTLSConfig, err)
+			}
+			certs = append(certs, cert)
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(opts); err != nil {
+			return fmt.Errorf("%w: %w", ErrTLSConfig, err)
+		}
+		return nil
+	}
+}
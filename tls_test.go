@@ -0,0 +1,375 @@
+package dsn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sgaunet/dsn/v3"
+)
+
+// testPKI holds PEM files generated for the TLS tests: a CA, a server certificate for
+// db.example.com signed by the CA, a client certificate and key, and an unrelated self-signed certificate.
+type testPKI struct {
+	caFile         string
+	clientCertFile string
+	clientKeyFile  string
+	clientBothFile string
+	serverCert     *x509.Certificate
+	otherCert      *x509.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, caCert := newCertificate(t, "test CA", nil, nil, true)
+	_, serverCert := newCertificate(t, "db.example.com", caCert, caKey, false)
+	clientKey, clientCert := newCertificate(t, "client", caCert, caKey, false)
+	_, otherCert := newCertificate(t, "other", nil, nil, false)
+
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientCert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	pki := &testPKI{
+		caFile:         filepath.Join(dir, "ca.pem"),
+		clientCertFile: filepath.Join(dir, "client.pem"),
+		clientKeyFile:  filepath.Join(dir, "client.key"),
+		clientBothFile: filepath.Join(dir, "client-both.pem"),
+		serverCert:     serverCert,
+		otherCert:      otherCert,
+	}
+	files := map[string][]byte{
+		pki.caFile:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}),
+		pki.clientCertFile: certPEM,
+		pki.clientKeyFile:  keyPEM,
+		pki.clientBothFile: append(append([]byte{}, certPEM...), keyPEM...),
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pki
+}
+
+func newCertificate(
+	t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool,
+) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+func TestDSN_TLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := []struct {
+		name               string
+		dsnToTest          string
+		wantNil            bool
+		wantServerName     string
+		wantInsecure       bool
+		wantVerifyChain    bool
+		wantRootCAs        bool
+		wantCertificates   int
+		wantMinVersion     uint16
+		wantErrIs          error
+		wantErrNotExisting bool
+	}{
+		{
+			name:      "postgres disable",
+			dsnToTest: "postgres://db.example.com/db?sslmode=disable",
+			wantNil:   true,
+		},
+		{
+			name:           "postgres without sslmode prefers TLS",
+			dsnToTest:      "postgres://db.example.com/db",
+			wantServerName: "db.example.com",
+			wantInsecure:   true,
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:           "postgres require",
+			dsnToTest:      "postgres://db.example.com/db?sslmode=require",
+			wantServerName: "db.example.com",
+			wantInsecure:   true,
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:            "postgres require with sslrootcert checks the chain",
+			dsnToTest:       "postgres://db.example.com/db?sslmode=require&sslrootcert=" + pki.caFile,
+			wantServerName:  "db.example.com",
+			wantInsecure:    true,
+			wantVerifyChain: true,
+			wantRootCAs:     true,
+			wantMinVersion:  tls.VersionTLS12,
+		},
+		{
+			name:            "postgres verify-ca",
+			dsnToTest:       "postgres://10.0.0.1/db?sslmode=verify-ca&sslrootcert=" + pki.caFile,
+			wantServerName:  "10.0.0.1",
+			wantInsecure:    true,
+			wantVerifyChain: true,
+			wantRootCAs:     true,
+			wantMinVersion:  tls.VersionTLS12,
+		},
+		{
+			name: "postgres verify-full with client certificate",
+			dsnToTest: "postgres://db.example.com/db?sslmode=verify-full&sslrootcert=" + pki.caFile +
+				"&sslcert=" + pki.clientCertFile + "&sslkey=" + pki.clientKeyFile +
+				"&ssl_min_protocol_version=TLSv1.3",
+			wantServerName:   "db.example.com",
+			wantRootCAs:      true,
+			wantCertificates: 1,
+			wantMinVersion:   tls.VersionTLS13,
+		},
+		{
+			name:           "postgres sslrootcert=system defaults to verify-full",
+			dsnToTest:      "postgres://u@db.example.com/db?sslrootcert=system",
+			wantServerName: "db.example.com",
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:           "postgres sslrootcert=system with verify-full",
+			dsnToTest:      "postgres://db.example.com/db?sslmode=verify-full&sslrootcert=system",
+			wantServerName: "db.example.com",
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:      "postgres sslrootcert=system rejects require",
+			dsnToTest: "postgres://db.example.com/db?sslmode=require&sslrootcert=system",
+			wantErrIs: dsn.ErrTLSConfig,
+		},
+		{
+			name:      "postgres sslrootcert=system rejects verify-ca",
+			dsnToTest: "postgres://db.example.com/db?sslmode=verify-ca&sslrootcert=system",
+			wantErrIs: dsn.ErrTLSConfig,
+		},
+		{
+			name:      "postgres sslrootcert=system rejects disable",
+			dsnToTest: "postgres://db.example.com/db?sslmode=disable&sslrootcert=system",
+			wantErrIs: dsn.ErrTLSConfig,
+		},
+		{
+			name:               "postgres missing sslrootcert",
+			dsnToTest:          "postgres://db.example.com/db?sslmode=verify-full&sslrootcert=/nonexistent/root.crt",
+			wantErrIs:          dsn.ErrTLSConfig,
+			wantErrNotExisting: true,
+		},
+		{
+			name:      "postgres sslcert without sslkey",
+			dsnToTest: "postgres://db.example.com/db?sslmode=require&sslcert=" + pki.clientCertFile,
+			wantErrIs: dsn.ErrTLSConfig,
+		},
+		{
+			name:      "postgres unix socket",
+			dsnToTest: "postgres:///db?host=/var/run/postgresql&sslmode=require",
+			wantNil:   true,
+		},
+		{
+			name:      "mysql without tls",
+			dsnToTest: "mysql://db.example.com/db",
+			wantNil:   true,
+		},
+		{
+			name:           "mysql tls=true",
+			dsnToTest:      "user:pass@tcp(db.example.com:3306)/db?tls=true",
+			wantServerName: "db.example.com",
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:           "mysql tls=skip-verify",
+			dsnToTest:      "mysql://db.example.com/db?tls=skip-verify",
+			wantServerName: "db.example.com",
+			wantInsecure:   true,
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:      "mysql custom tls config",
+			dsnToTest: "mysql://db.example.com/db?tls=custom",
+			wantErrIs: dsn.ErrTLSConfig,
+		},
+		{
+			name:      "redis",
+			dsnToTest: "redis://db.example.com:6379/0",
+			wantNil:   true,
+		},
+		{
+			name:           "rediss",
+			dsnToTest:      "rediss://db.example.com:6379/0",
+			wantServerName: "db.example.com",
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:           "redis skip_verify",
+			dsnToTest:      "redis://db.example.com:6379/0?tls=true&skip_verify=true",
+			wantServerName: "db.example.com",
+			wantInsecure:   true,
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name: "mongodb tls with files",
+			dsnToTest: "mongodb://db.example.com/db?tls=true&tlsCAFile=" + pki.caFile +
+				"&tlsCertificateKeyFile=" + pki.clientBothFile,
+			wantServerName:   "db.example.com",
+			wantRootCAs:      true,
+			wantCertificates: 1,
+			wantMinVersion:   tls.VersionTLS12,
+		},
+		{
+			name:           "mongodb+srv uses TLS by default",
+			dsnToTest:      "mongodb+srv://cluster.example.com/db",
+			wantServerName: "cluster.example.com",
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:      "mongodb+srv with tls=false",
+			dsnToTest: "mongodb+srv://cluster.example.com/db?tls=false",
+			wantNil:   true,
+		},
+		{
+			name:           "sqlserver encrypt",
+			dsnToTest:      "Server=db.example.com;Database=db;Encrypt=True;TrustServerCertificate=true",
+			wantServerName: "db.example.com",
+			wantInsecure:   true,
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:      "sqlserver encrypt disabled",
+			dsnToTest: "sqlserver://db.example.com?database=db&encrypt=disable",
+			wantNil:   true,
+		},
+		{
+			name:      "sqlite",
+			dsnToTest: "sqlite:///tmp/db.sqlite",
+			wantNil:   true,
+		},
+		{
+			name:           "unknown scheme with tls parameter",
+			dsnToTest:      "foo://db.example.com/db?ssl=true",
+			wantServerName: "db.example.com",
+			wantMinVersion: tls.VersionTLS12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dsn.New(tt.dsnToTest)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			config, err := d.TLSConfig()
+			if !errors.Is(err, tt.wantErrIs) {
+				t.Fatalf("DSN.TLSConfig() error = %v, want %v", err, tt.wantErrIs)
+			}
+			if tt.wantErrNotExisting && !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("DSN.TLSConfig() error = %v, want fs.ErrNotExist", err)
+			}
+			if err != nil {
+				return
+			}
+			if (config == nil) != tt.wantNil {
+				t.Fatalf("DSN.TLSConfig() = %v, want nil %v", config, tt.wantNil)
+			}
+			if config == nil {
+				return
+			}
+			if config.ServerName != tt.wantServerName {
+				t.Errorf("ServerName = %q, want %q", config.ServerName, tt.wantServerName)
+			}
+			if config.InsecureSkipVerify != tt.wantInsecure {
+				t.Errorf("InsecureSkipVerify = %v, want %v", config.InsecureSkipVerify, tt.wantInsecure)
+			}
+			if (config.VerifyPeerCertificate != nil) != tt.wantVerifyChain {
+				t.Errorf("VerifyPeerCertificate set = %v, want %v", config.VerifyPeerCertificate != nil, tt.wantVerifyChain)
+			}
+			if (config.RootCAs != nil) != tt.wantRootCAs {
+				t.Errorf("RootCAs set = %v, want %v", config.RootCAs != nil, tt.wantRootCAs)
+			}
+			if len(config.Certificates) != tt.wantCertificates {
+				t.Errorf("len(Certificates) = %d, want %d", len(config.Certificates), tt.wantCertificates)
+			}
+			if config.MinVersion != tt.wantMinVersion {
+				t.Errorf("MinVersion = %x, want %x", config.MinVersion, tt.wantMinVersion)
+			}
+		})
+	}
+}
+
+func TestDSN_TLSConfigVerification(t *testing.T) {
+	pki := newTestPKI(t)
+
+	// verify-ca accepts a certificate signed by the CA whatever the host name
+	d, err := dsn.New("postgres://10.0.0.1/db?sslmode=verify-ca&sslrootcert=" + pki.caFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	config, err := d.TLSConfig()
+	if err != nil {
+		t.Fatalf("DSN.TLSConfig() error = %v", err)
+	}
+	if err := config.VerifyPeerCertificate([][]byte{pki.serverCert.Raw}, nil); err != nil {
+		t.Errorf("verify-ca rejected a certificate signed by the CA: %v", err)
+	}
+	if err := config.VerifyPeerCertificate([][]byte{pki.otherCert.Raw}, nil); !errors.Is(err, dsn.ErrTLSConfig) {
+		t.Errorf("verify-ca accepted a certificate not signed by the CA: %v", err)
+	}
+
+	// verify-full checks the host name against the roots of the DSN
+	d, err = dsn.New("postgres://db.example.com/db?sslmode=verify-full&sslrootcert=" + pki.caFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	config, err = d.TLSConfig()
+	if err != nil {
+		t.Fatalf("DSN.TLSConfig() error = %v", err)
+	}
+	opts := x509.VerifyOptions{Roots: config.RootCAs, DNSName: config.ServerName}
+	if _, err := pki.serverCert.Verify(opts); err != nil {
+		t.Errorf("verify-full configuration rejects the server certificate: %v", err)
+	}
+	opts.DNSName = "other.example.com"
+	if _, err := pki.serverCert.Verify(opts); err == nil {
+		t.Error("verify-full configuration accepts a certificate for another host")
+	}
+}
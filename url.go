@@ -0,0 +1,139 @@
+package dsn
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseURL parses a DSN in the URL format:
+// <scheme>://<user>:<password>@<host1>:<port1>,<host2>:<port2>/<dbname>?<parameters>.
+// The scheme is optional. url.Parse is not used because it does not support multiple hosts.
+func parseURL(dsn string) (*DSN, error) {
+	if strings.IndexFunc(dsn, isControl) >= 0 {
+		return nil, fmt.Errorf("%w: invalid control character", ErrInvalidDSNFormat)
+	}
+
+	// Extract scheme
+	scheme := ""
+	rest := dsn
+	if i := strings.Index(dsn, "://"); i >= 0 {
+		scheme, rest = strings.ToLower(dsn[:i]), dsn[i+len("://"):]
+		if !isValidScheme(scheme) {
+			return nil, fmt.Errorf("%w: invalid scheme %q", ErrInvalidDSNFormat, scheme)
+		}
+	}
+
+	// Drop the fragment, like url.Parse does
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+
+	authority, path := rest, ""
+	if i := indexOutsideParens(rest, '/'); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+
+	// Extract user and password
+	user := ""
+	password := ""
+	hostList := authority
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo := authority[:i]
+		hostList = authority[i+1:]
+		rawUser, rawPassword, _ := strings.Cut(userinfo, ":")
+		var err error
+		if user, err = unescape(rawUser); err != nil {
+			return nil, err
+		}
+		if password, err = unescape(rawPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	// Extract hosts and ports
+	hosts, err := parseHosts(hostList)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract path (dbname)
+	dbname, err := unescape(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract parameters
+	params, err := parseParameters(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	d := DSN{
+		dsn:        dsn,
+		scheme:     scheme,
+		user:       user,
+		password:   password,
+		hosts:      hosts,
+		dbname:     dbname,
+		parameters: params,
+	}
+	return &d, nil
+}
+
+// indexOutsideParens returns the index of the first c in s which is not enclosed
+// in parentheses, or -1. It allows socket paths such as unix(/tmp/mysql.sock) in the authority.
+func indexOutsideParens(s string, c byte) int {
+	depth := 0
+	for i := range len(s) {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// unescape decodes a percent-encoded component of the DSN.
+func unescape(s string) (string, error) {
+	unescaped, err := url.PathUnescape(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidDSNFormat, err.Error())
+	}
+	if !utf8.ValidString(unescaped) {
+		return "", fmt.Errorf("%w: invalid UTF-8 in %q", ErrInvalidDSNFormat, s)
+	}
+	return unescaped, nil
+}
+
+// isValidScheme reports whether scheme follows RFC 3986: ALPHA *( ALPHA / DIGIT / "+" / "-" / "." ).
+func isValidScheme(scheme string) bool {
+	if scheme == "" {
+		return false
+	}
+	for i, c := range scheme {
+		switch {
+		case 'a' <= c && c <= 'z':
+		case ('0' <= c && c <= '9') || c == '+' || c == '-' || c == '.':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isControl reports whether r is an ASCII control character.
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f
+}
@@ -0,0 +1,270 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxPort is the highest TCP port number.
+const maxPort = 65535
+
+// FieldError describes a component of the DSN which is not valid.
+type FieldError struct {
+	// Field is the name of the component: scheme, user, host, port, dbname, or parameter <name>.
+	Field string
+	// Value is the value of the component.
+	Value string
+	// Reason explains why the value is not valid.
+	Reason string
+}
+
+// Error returns the description of the invalid component.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// ValidationError is returned by Validate and holds every invalid component of the DSN.
+// Each one can be retrieved with errors.As and a *FieldError target.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error returns the descriptions of the invalid components, separated by semicolons.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the FieldErrors, for errors.Is and errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ValidateOption adds a check to Validate.
+type ValidateOption func(*validateOptions)
+
+// validateOptions holds the checks added by the options given to Validate.
+type validateOptions struct {
+	requireUser   bool
+	requirePort   bool
+	requireDBName bool
+
+	rejectUnknownParameters bool
+}
+
+// RequireUser makes Validate report a DSN without user.
+func RequireUser() ValidateOption {
+	return func(o *validateOptions) {
+		o.requireUser = true
+	}
+}
+
+// RequirePort makes Validate report a host without explicit port.
+func RequirePort() ValidateOption {
+	return func(o *validateOptions) {
+		o.requirePort = true
+	}
+}
+
+// RequireDBName makes Validate report a DSN without database name.
+func RequireDBName() ValidateOption {
+	return func(o *validateOptions) {
+		o.requireDBName = true
+	}
+}
+
+// RejectUnknownParameters makes Validate report every parameter unknown to the driver of the scheme.
+// Without it, only the unknown parameters close to a known one are reported, as likely typos.
+func RejectUnknownParameters() ValidateOption {
+	return func(o *validateOptions) {
+		o.rejectUnknownParameters = true
+	}
+}
+
+// Validate checks the components of the DSN and returns a *ValidationError listing
+// every invalid one, or nil if the DSN is valid.
+// Ports must be between 1 and 65535, a host is required by network schemes unless
+// a Unix socket is used, and the parameters must match the types and allowed values declared
+// by the driver registered for the scheme (e.g. the sslmode values of postgres).
+// A parameter unknown to the driver but close to a known one, such as sslmod, is reported as a typo.
+// The driver then applies its own rules.
+func (d *DSN) Validate(opts ...ValidateOption) error {
+	var o validateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var errs []*FieldError
+	driver, hasDriver := d.Driver()
+	if hasDriver && driver.DefaultPort() != "" && d.GetHost() == "" && !d.IsUnixSocket() {
+		errs = append(errs, &FieldError{Field: "host", Reason: "required by scheme " + d.scheme})
+	}
+	for _, h := range d.hosts {
+		if h.Port == "" {
+			if o.requirePort {
+				errs = append(errs, &FieldError{Field: "port", Reason: "required for host " + h.Host})
+			}
+			continue
+		}
+		if port, err := strconv.Atoi(h.Port); err != nil || port < 1 || port > maxPort {
+			errs = append(errs, &FieldError{Field: "port", Value: h.Port, Reason: "must be a number between 1 and 65535"})
+		}
+	}
+	if o.requireUser && d.user == "" {
+		errs = append(errs, &FieldError{Field: "user", Reason: "required"})
+	}
+	if o.requireDBName && d.dbname == "" {
+		errs = append(errs, &FieldError{Field: "dbname", Reason: "required"})
+	}
+	if hasDriver {
+		errs = append(errs, d.parameterErrors(driver, o.rejectUnknownParameters)...)
+		errs = append(errs, fieldErrors(driver.Validate(d))...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// fieldErrors returns the FieldErrors held by an error returned by a driver.
+// Any other error is reported against the whole DSN.
+func fieldErrors(err error) []*FieldError {
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Errors
+	}
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return []*FieldError{fieldErr}
+	}
+	return []*FieldError{{Field: "dsn", Reason: err.Error()}}
+}
+
+// maxSuggestionDistance is the highest edit distance between an unknown parameter
+// and the known parameter suggested in its place, once both are folded by foldParameterName.
+const maxSuggestionDistance = 1
+
+// parameterErrors checks the parameters of the DSN against the specifications of the driver.
+// An unknown parameter is reported if it looks like a typo of a known one, or if rejectUnknown is set.
+func (d *DSN) parameterErrors(driver Driver, rejectUnknown bool) []*FieldError {
+	folder, ok := driver.(parameterFolder)
+	fold := ok && folder.foldsParameters()
+
+	var errs []*FieldError
+	for _, p := range d.parameters {
+		spec, known := lookupParameter(driver, p.key)
+		if !known {
+			if suggestion := suggestParameter(driver, p.key); suggestion != "" {
+				errs = append(errs, &FieldError{
+					Field:  "parameter " + p.key,
+					Value:  p.value,
+					Reason: fmt.Sprintf("unknown parameter, did you mean %q?", suggestion),
+				})
+			} else if rejectUnknown {
+				errs = append(errs, &FieldError{
+					Field:  "parameter " + p.key,
+					Value:  p.value,
+					Reason: "unknown parameter for scheme " + d.scheme,
+				})
+			}
+			continue
+		}
+		if reason := checkParameterValue(spec, p.value, fold); reason != "" {
+			errs = append(errs, &FieldError{Field: "parameter " + p.key, Value: p.value, Reason: reason})
+		}
+	}
+	return errs
+}
+
+// checkParameterValue returns why value does not match the specification, or an empty string.
+func checkParameterValue(spec ParameterSpec, value string, fold bool) string {
+	if len(spec.Allowed) > 0 {
+		for _, allowed := range spec.Allowed {
+			if value == allowed || (fold && strings.EqualFold(value, allowed)) {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(spec.Allowed, ", ")
+	}
+
+	var err error
+	switch spec.Type {
+	case ParameterInt:
+		_, err = strconv.Atoi(value)
+	case ParameterBool:
+		_, err = parseBool(value)
+	case ParameterDuration:
+		_, err = parseDuration(value)
+	case ParameterStrictDuration:
+		_, err = time.ParseDuration(value)
+	case ParameterString:
+	}
+	if err != nil {
+		if spec.Type == ParameterInt {
+			return "must be an integer"
+		}
+		return "must be a " + spec.Type.String()
+	}
+	return ""
+}
+
+// suggestParameter returns the known parameter closest to name, ignoring case and separators,
+// or an empty string if none is close enough to be a typo.
+func suggestParameter(driver Driver, name string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, spec := range driver.Parameters() {
+		distance := levenshtein(foldParameterName(name), foldParameterName(spec.Name))
+		if distance < bestDistance {
+			best, bestDistance = spec.Name, distance
+		}
+	}
+	return best
+}
+
+// foldParameterName lowercases a parameter name and drops its separators,
+// so that connect-timeout, ConnectTimeout and connect_timeout compare equal.
+func foldParameterName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', ' ', '.':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}